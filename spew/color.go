@@ -0,0 +1,102 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"io"
+	"os"
+)
+
+// ColorScheme holds the ANSI escape sequences, such as "\x1b[32m" for green,
+// used to colorize each kind of output when Config.Color is set.  An empty
+// sequence leaves that kind of output uncolored.
+type ColorScheme struct {
+	// Type is used for type information such as (int).
+	Type string
+
+	// Field is used for the names of struct fields.
+	Field string
+
+	// String is used for string values.
+	String string
+
+	// Number is used for numeric and bool values.
+	Number string
+
+	// Nil is used for <nil> and <invalid>.
+	Nil string
+
+	// Address is used for pointer addresses, as well as uintptr, unsafe
+	// pointer, channel and func values.
+	Address string
+
+	// Marker is used for the <already shown> and <max depth reached>
+	// markers, and their short forms in the Formatter.
+	Marker string
+}
+
+// DefaultColorScheme is the ColorScheme used when Config.Color is set and
+// Config.ColorScheme is nil.
+var DefaultColorScheme = ColorScheme{
+	Type:    "\x1b[36m",
+	Field:   "\x1b[34m",
+	String:  "\x1b[32m",
+	Number:  "\x1b[33m",
+	Nil:     "\x1b[35m",
+	Address: "\x1b[90m",
+	Marker:  "\x1b[31m",
+}
+
+// colorResetBytes is the ANSI escape sequence which ends a colored span.
+var colorResetBytes = []byte("\x1b[0m")
+
+// colorsFor returns the ColorScheme to use for output to w, which is nil if
+// it isn't known, according to the Color options of cfg.  The zero value,
+// which colorizes nothing, is returned when colors are disabled.
+func colorsFor(cfg *Config, w io.Writer) ColorScheme {
+	if !cfg.Color || os.Getenv("NO_COLOR") != "" {
+		return ColorScheme{}
+	}
+	if cfg.IsTerminal != nil && !cfg.IsTerminal(w) {
+		return ColorScheme{}
+	}
+	if cfg.ColorScheme != nil {
+		return *cfg.ColorScheme
+	}
+	return DefaultColorScheme
+}
+
+// setColor writes the escape sequence color to w, if there is one.
+func setColor(w io.Writer, color string) {
+	if color != "" {
+		io.WriteString(w, color)
+	}
+}
+
+// resetColor ends the span started by setColor with the same color.
+func resetColor(w io.Writer, color string) {
+	if color != "" {
+		w.Write(colorResetBytes)
+	}
+}
+
+// writeColor writes b to w colored with the escape sequence color.
+func writeColor(w io.Writer, color string, b []byte) {
+	setColor(w, color)
+	w.Write(b)
+	resetColor(w, color)
+}
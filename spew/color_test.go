@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// colorStruct is used to test the colors of field names and nil pointers.
+type colorStruct struct {
+	Name string
+	Ptr  *int
+}
+
+// colorNode is used to test the colors of circular references.
+type colorNode struct {
+	Next *colorNode
+}
+
+// colorScheme marks the start of each kind of output with a readable prefix.
+// The tests replace the reset sequence with "]" to make them easy to read.
+var colorScheme = spew.ColorScheme{
+	Type:    "T[",
+	Field:   "F[",
+	String:  "S[",
+	Number:  "N[",
+	Nil:     "Z[",
+	Address: "A[",
+	Marker:  "M[",
+}
+
+// TestColor executes all of the tests described by colorTests.
+func TestColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	cfg := &spew.Config{Indent: " ", Color: true, ColorScheme: &colorScheme,
+		DisablePointerAddresses: true, DisableCapacities: true}
+	cfgMaxDepth := &spew.Config{Indent: " ", Color: true, ColorScheme: &colorScheme,
+		DisableCapacities: true, MaxDepth: 1}
+	cfgNotTerminal := &spew.Config{Indent: " ", Color: true, ColorScheme: &colorScheme,
+		IsTerminal: func(w io.Writer) bool { return false }}
+
+	n := &colorNode{}
+	n.Next = n
+
+	tests := []outputTest{
+		{line(), cfg, "", 5, "T[(int)] N[5]\n"},
+		{line(), cfg, "", nil, "T[(interface {})] Z[<nil>]\n"},
+		{line(), cfg, "", colorStruct{"x", nil}, "T[(spew_test.colorStruct)] {\n" +
+			" F[Name]: T[(string)] (len=1) S[\"x\"],\n" +
+			" F[Ptr]: T[(*int)](Z[<nil>])\n" +
+			"}\n"},
+		{line(), cfg, "", n, "T[(*spew_test.colorNode)]({\n" +
+			" F[Next]: T[(*spew_test.colorNode)](M[<already shown>])\n" +
+			"})\n"},
+		{line(), cfgMaxDepth, "", [][]int{{1}}, "T[([][]int)] (len=1) {\n" +
+			" T[([]int)] (len=1) {\n" +
+			"  M[<max depth reached>]\n" +
+			" }\n" +
+			"}\n"},
+		{line(), cfg, "", uintptr(0x10), "T[(uintptr)] A[0x10]\n"},
+		{line(), cfgNotTerminal, "", 5, "(int) 5\n"},
+		{line(), cfg, "%v", 5, "N[5]"},
+		{line(), cfg, "%#v", true, "T[(bool)]N[true]"},
+		{line(), cfg, "%#v", nil, "T[(interface {})]Z[<nil>]"},
+		{line(), cfg, "%+v", colorStruct{"x", nil}, "{F[Name]:S[x] F[Ptr]:Z[<nil>]}"},
+		{line(), cfg, "%v", n, "<*>{<*>M[<shown>]}"},
+		{line(), cfgMaxDepth, "%v", [][]int{{1}}, "[[M[<max>]]]"},
+		{line(), cfgNotTerminal, "%v", 5, "5"},
+	}
+
+	runOutputTestsFunc(t, tests, func(s string) string {
+		return strings.ReplaceAll(s, "\x1b[0m", "]")
+	})
+
+	// The hint is passed the writer the output is destined for.
+	var terminal, other bytes.Buffer
+	cfgHint := &spew.Config{Color: true, ColorScheme: &colorScheme,
+		IsTerminal: func(w io.Writer) bool { return w == &terminal }}
+	cfgHint.Fdump(&terminal, 1)
+	cfgHint.Fdump(&other, 1)
+	cfgHint.Fprint(&terminal, 2)
+	cfgHint.Fprint(&other, 2)
+	if s := strings.ReplaceAll(terminal.String(), "\x1b[0m", "]"); s != "T[(int)] N[1]\nN[2]" {
+		t.Errorf("IsTerminal true:\n got: %s", s)
+	}
+	if s := other.String(); s != "(int) 1\n2" {
+		t.Errorf("IsTerminal false:\n got: %s", s)
+	}
+
+	cfgDefault := &spew.Config{Color: true}
+	if s := cfgDefault.Sdump(1); s != "\x1b[36m(int)\x1b[0m \x1b[33m1\x1b[0m\n" {
+		t.Errorf("DefaultColorScheme:\n got: %q", s)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if s := cfgDefault.Sdump(1); s != "(int) 1\n" {
+		t.Errorf("NO_COLOR:\n got: %q", s)
+	}
+}
@@ -18,11 +18,17 @@ package spew
 
 import (
 	"bytes"
+	"context"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"sort"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 // Some constants in the form of bytes to avoid string overhead.  This mirrors
@@ -67,6 +73,12 @@ var (
 	closeMapBytes         = []byte("]")
 	lenEqualsBytes        = []byte("len=")
 	capEqualsBytes        = []byte("cap=")
+	diffArrowBytes        = []byte(" -> ")
+	missingBytes          = []byte("<missing>")
+	redactedBytes         = []byte("<redacted>")
+	hexPrefixBytes        = []byte("0x")
+	minusBytes            = []byte("-")
+	ampersandBytes        = []byte("&")
 )
 
 // hexDigits is used to map a decimal value to a hex digit.
@@ -82,12 +94,151 @@ func catchPanic(w io.Writer, v reflect.Value) {
 	}
 }
 
-// handleMethods attempts to call the Error and String methods on the underlying
-// type the passed reflect.Value represents and outputes the result to Writer w.
+// indexPath returns the path of the element at index i of the array or slice
+// at path.
+func indexPath(path string, i int) string {
+	return path + "[" + strconv.Itoa(i) + "]"
+}
+
+// keyPath returns the path of the entry with the passed key in the map at
+// path.  String keys are quoted.
+func keyPath(cfg *Config, path string, key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return path + "[" + strconv.Quote(key.String()) + "]"
+	}
+	return path + "[" + sprintValue(cfg, key, "") + "]"
+}
+
+// omitValue reports whether the struct field or map entry with value v should
+// be skipped according to the OmitNilFields and OmitZeroFields options of cfg.
+func omitValue(cfg *Config, v reflect.Value) bool {
+	if cfg.OmitZeroFields {
+		return v.IsZero()
+	}
+	if !cfg.OmitNilFields {
+		return false
+	}
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map,
+		reflect.Ptr, reflect.Slice, reflect.UnsafePointer:
+		return v.IsNil()
+	}
+	return false
+}
+
+// mapKeys returns the keys of the entries of the map v which are output,
+// sorted if the SortKeys option of cfg is set.  Entries skipped due to the
+// OmitNilFields and OmitZeroFields options are left out.
+func mapKeys(cfg *Config, v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	if cfg.OmitNilFields || cfg.OmitZeroFields {
+		kept := keys[:0]
+		for _, key := range keys {
+			if !omitValue(cfg, v.MapIndex(key)) {
+				kept = append(kept, key)
+			}
+		}
+		keys = kept
+	}
+	if cfg.SortKeys {
+		sortValues(keys, cfg)
+	}
+	return keys
+}
+
+// structField describes a struct field which is output, along with the
+// options from its spew struct tag.
+type structField struct {
+	index  int
+	redact bool
+	hex    bool
+
+	// hidden is set for unexported fields when DisableUnexported is set.
+	// They are not output, but are still separated from the fields around
+	// them as they always have been.
+	hidden bool
+}
+
+// fieldTag returns the options from the spew struct tag of field i of the
+// struct v, and whether the field is output at all.  A spew struct tag is a
+// comma-separated list of the options "-" to skip the field, "redact" to
+// output <redacted> in place of its value, "hex" to output the integers within
+// it in hexadecimal and "omitempty" to skip it when its value is the zero
+// value for its type.  Every output format uses it so that they all agree on
+// which fields are shown.
+func fieldTag(v reflect.Value, i int) (field structField, ok bool) {
+	field.index = i
+	ok = true
+	for _, opt := range strings.Split(v.Type().Field(i).Tag.Get("spew"), ",") {
+		switch opt {
+		case "-":
+			ok = false
+		case "redact":
+			field.redact = true
+		case "hex":
+			field.hex = true
+		case "omitempty":
+			ok = ok && !v.Field(i).IsZero()
+		}
+	}
+	return field, ok
+}
+
+// structFields returns the fields of the struct v, leaving out those which are
+// skipped according to the OmitNilFields and OmitZeroFields options of cfg and
+// the spew struct tags of the fields.  Unexported fields are marked hidden
+// when the DisableUnexported option is set.
+func structFields(cfg *Config, v reflect.Value) []structField {
+	vt := v.Type()
+	fields := make([]structField, 0, vt.NumField())
+	for i := 0; i < vt.NumField(); i++ {
+		// StructField has an IsExported() method, but only in 1.17+.
+		if cfg.DisableUnexported && vt.Field(i).PkgPath != "" {
+			fields = append(fields, structField{index: i, hidden: true})
+			continue
+		}
+
+		field, ok := fieldTag(v, i)
+		if ok && !omitValue(cfg, v.Field(i)) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// handleFormatter calls the custom formatter registered with cfg for the type
+// of v, if there is one, and reports whether it did so.  It handles panics in
+// the formatter the same way as handleMethods.
+func handleFormatter(cfg *Config, w io.Writer, v reflect.Value, ctx Context) (handled bool) {
+	fn, ok := cfg.formatters[v.Type()]
+	if !ok {
+		return false
+	}
+
+	// Give the formatter a value it can call Interface on, even for unexported
+	// struct fields.  That isn't possible without the unsafe package, in
+	// which case the value is output as though there were no formatter.
+	if !v.CanInterface() {
+		if UnsafeDisabled {
+			return false
+		}
+		v = unsafeReflectValue(v)
+	}
+	defer catchPanic(w, v)
+	fn(w, v, ctx)
+	return true
+}
+
+// handleMethods attempts to call the SpewDump, Error and String methods on the
+// underlying type the passed reflect.Value represents and outputes the result
+// to Writer w.  SpewDump is passed ctx.
 //
 // It handles panics in any called methods by catching and displaying the error
 // as the formatted value.
-func handleMethods(cfg *Config, w io.Writer, v reflect.Value) (handled bool) {
+func handleMethods(cfg *Config, w io.Writer, v reflect.Value, ctx Context) (handled bool) {
 	// We need an interface to check if the type implements the error or
 	// Stringer interface.  However, the reflect package won't give us an
 	// interface on certain things like unexported struct fields in order
@@ -115,11 +266,19 @@ func handleMethods(cfg *Config, w io.Writer, v reflect.Value) (handled bool) {
 		v = v.Addr()
 	}
 
-	// Is it an error or Stringer?
+	// Is it a SpewDumper, error or Stringer?
 	switch iface := v.Interface().(type) {
+	case SpewDumper:
+		defer catchPanic(w, v)
+		iface.SpewDump(w, ctx)
+		return true
+
 	case error:
 		defer catchPanic(w, v)
-		s := iface.Error()
+		s, ok := callMethod(ctx.done, iface.Error)
+		if !ok {
+			return true
+		}
 		if cfg.QuoteStrings {
 			s = strconv.Quote(s)
 		}
@@ -136,7 +295,10 @@ func handleMethods(cfg *Config, w io.Writer, v reflect.Value) (handled bool) {
 
 	case fmt.Stringer:
 		defer catchPanic(w, v)
-		s := iface.String()
+		s, ok := callMethod(ctx.done, iface.String)
+		if !ok {
+			return true
+		}
 		if cfg.QuoteStrings {
 			s = strconv.Quote(s)
 		}
@@ -153,6 +315,89 @@ func handleMethods(cfg *Config, w io.Writer, v reflect.Value) (handled bool) {
 	return false
 }
 
+// callMethod calls the Error or String method fn and returns its result.  When
+// done is set, the method is called on another goroutine and ok is false if
+// done is closed before it returns, in which case it is left running.  A panic
+// in the method is passed on to the caller.
+func callMethod(done <-chan struct{}, fn func() string) (s string, ok bool) {
+	if done == nil {
+		return fn(), true
+	}
+
+	type result struct {
+		s        string
+		panicked bool
+		err      any
+	}
+	results := make(chan result, 1)
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				results <- result{panicked: true, err: err}
+			}
+		}()
+		results <- result{s: fn()}
+	}()
+
+	select {
+	case r := <-results:
+		if r.panicked {
+			panic(r.err)
+		}
+		return r.s, true
+	case <-done:
+		return "", false
+	}
+}
+
+// countPointerRefs walks v and counts how many times each pointer is reached
+// into refs.  It does not descend into a pointer more than once, so it
+// terminates on circular data structures, and any pointer which is part of a
+// cycle is counted more than once.
+func countPointerRefs(cfg *Config, v reflect.Value, refs map[uintptr]int) {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		addr := v.Pointer()
+		refs[addr]++
+		if refs[addr] == 1 {
+			countPointerRefs(cfg, v.Elem(), refs)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			countPointerRefs(cfg, v.Index(i), refs)
+		}
+
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			countPointerRefs(cfg, iter.Key(), refs)
+			countPointerRefs(cfg, iter.Value(), refs)
+		}
+
+	case reflect.Struct:
+		vt := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			// StructField has an IsExported() method, but only in 1.17+.
+			if cfg.DisableUnexported && vt.Field(i).PkgPath != "" {
+				continue
+			}
+			// Skipped and redacted fields are never output, so pointers
+			// reached only through them are not shared.
+			if field, ok := fieldTag(v, i); !ok || field.redact {
+				continue
+			}
+			countPointerRefs(cfg, v.Field(i), refs)
+		}
+	}
+}
+
 // printBool outputs a boolean value as true or false to Writer w.
 func printBool(w io.Writer, val bool) {
 	if val {
@@ -172,6 +417,223 @@ func printUint(w io.Writer, val uint64, base int) {
 	w.Write([]byte(strconv.FormatUint(val, base)))
 }
 
+// truncate returns how many of the first and last of n elements are output
+// with a limit of max, which means there is no limit when it is 0.  Only the
+// first are output unless the ElideMiddle option of cfg is set.
+func truncate(cfg *Config, n, max int) (head, tail int) {
+	if max <= 0 || n <= max {
+		return n, 0
+	}
+	if cfg.ElideMiddle {
+		return (max + 1) / 2, max / 2
+	}
+	return max, 0
+}
+
+// moreBytes returns the marker which is output in place of more elided
+// elements or bytes.
+func moreBytes(more int) []byte {
+	return []byte("... (" + strconv.Itoa(more) + " more)")
+}
+
+// printString outputs s to Writer w, quoted if quote is set, eliding the bytes
+// in excess of max.  The string is only cut at
+// rune boundaries.
+func printString(w io.Writer, cfg *Config, colors ColorScheme, s string, quote bool, max int) {
+	printPart := func(part string) {
+		if quote {
+			part = strconv.Quote(part)
+		}
+		writeColor(w, colors.String, []byte(part))
+	}
+
+	head, tail := truncate(cfg, len(s), max)
+	if head+tail >= len(s) {
+		printPart(s)
+		return
+	}
+	for head > 0 && !utf8.RuneStart(s[head]) {
+		head--
+	}
+	start := len(s) - tail
+	for start < len(s) && !utf8.RuneStart(s[start]) {
+		start++
+	}
+
+	printPart(s[:head])
+	w.Write(spaceBytes)
+	writeColor(w, colors.Marker, moreBytes(start-head))
+	if start < len(s) {
+		w.Write(spaceBytes)
+		printPart(s[start:])
+	}
+}
+
+// countingWriter is an io.Writer which counts the bytes written to w and
+// records the first error, so that output can be stopped on a write error, or
+// cut short once ctx is done or the MaxBytes option is exceeded.  A nil
+// countingWriter never stops.
+type countingWriter struct {
+	w   io.Writer
+	max int
+	n   int
+	err error
+
+	ctx  context.Context
+	done <-chan struct{}
+
+	// truncated is set once the output has been cut short.  canceled is
+	// the error of ctx if it was the cause.
+	truncated bool
+	canceled  error
+}
+
+// newCountingWriter returns a countingWriter for Writer w with the MaxBytes
+// option of cfg, which stops once ctx is done.
+func newCountingWriter(ctx context.Context, cfg *Config, w io.Writer) *countingWriter {
+	return &countingWriter{w: w, max: cfg.MaxBytes, ctx: ctx, done: ctx.Done()}
+}
+
+// Write writes p to the underlying Writer, counting the bytes written.  Once
+// a write has failed, nothing more is written and the error is returned.
+func (b *countingWriter) Write(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	n, err := b.w.Write(p)
+	b.n += n
+	b.err = err
+	return n, err
+}
+
+// ctxDone returns the Done channel of ctx, or nil for a nil countingWriter.
+func (b *countingWriter) ctxDone() <-chan struct{} {
+	if b == nil {
+		return nil
+	}
+	return b.done
+}
+
+// stop reports whether a write has failed, ctx is done or the budget is
+// exhausted, in which case the caller must not output any further values.
+// The output is marked as truncated in the latter two cases.
+func (b *countingWriter) stop() bool {
+	switch {
+	case b == nil:
+		return false
+	case b.err != nil:
+		return true
+	}
+	select {
+	case <-b.done:
+		b.truncate(b.ctx.Err())
+		return true
+	default:
+	}
+	if b.max <= 0 || b.n < b.max {
+		return false
+	}
+	b.truncate(nil)
+	return true
+}
+
+// truncate marks the output as cut short because of canceled, if set, unless
+// it already is.
+func (b *countingWriter) truncate(canceled error) {
+	if !b.truncated {
+		b.truncated = true
+		b.canceled = canceled
+	}
+}
+
+// truncatedBytes returns the marker which ends output which was cut short, or
+// nil if it was not.  The count in the marker is everything written before it,
+// including the part of a value which was cut short and anything closing the
+// values around it.
+func (b *countingWriter) truncatedBytes() []byte {
+	if b == nil || !b.truncated || b.err != nil {
+		return nil
+	}
+	marker := "<truncated after " + strconv.Itoa(b.n) + " bytes"
+	if b.canceled != nil {
+		marker += ": " + b.canceled.Error()
+	}
+	return []byte(marker + ">")
+}
+
+// limit returns the maximum number of bytes of a string or byte slice of n
+// bytes to output, which is max lowered to what remains of the budget so that
+// a single huge value cannot blow it.  The output is marked as truncated if
+// the budget cuts the value short.
+func (b *countingWriter) limit(max, n int) int {
+	if b == nil || b.max <= 0 {
+		return max
+	}
+	remaining := b.max - b.n
+	if remaining < 1 {
+		remaining = 1
+	}
+	if (max <= 0 || remaining < max) && remaining < n {
+		b.truncate(nil)
+		return remaining
+	}
+	return max
+}
+
+// hexDumpAt returns the hexdump of buf, with offsets which start at offset
+// rather than 0.
+func hexDumpAt(buf []byte, offset int) string {
+	lines := strings.SplitAfter(hex.Dump(buf), "\n")
+	for i, line := range lines {
+		if len(line) < 8 {
+			continue
+		}
+		if n, err := strconv.ParseUint(line[:8], 16, 64); err == nil {
+			lines[i] = fmt.Sprintf("%08x", n+uint64(offset)) + line[8:]
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// printHexInt outputs a signed integer value in hexadecimal with a 0x prefix
+// to Writer w.
+func printHexInt(w io.Writer, val int64) {
+	u := uint64(val)
+	if val < 0 {
+		w.Write(minusBytes)
+		u = uint64(-val)
+	}
+	printHexUint(w, u)
+}
+
+// printHexUint outputs an unsigned integer value in hexadecimal with a 0x
+// prefix to Writer w.
+func printHexUint(w io.Writer, val uint64) {
+	w.Write(hexPrefixBytes)
+	w.Write([]byte(strconv.FormatUint(val, 16)))
+}
+
+// formatInt returns the integer v, which must be of a signed or unsigned
+// integer kind, in base 10, or in hexadecimal with a 0x prefix if hex is set.
+func formatInt(v reflect.Value, hex bool) string {
+	var buf bytes.Buffer
+	switch v.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		if hex {
+			printHexInt(&buf, v.Int())
+		} else {
+			printInt(&buf, v.Int(), 10)
+		}
+	default:
+		if hex {
+			printHexUint(&buf, v.Uint())
+		} else {
+			printUint(&buf, v.Uint(), 10)
+		}
+	}
+	return buf.String()
+}
+
 // printFloat outputs a floating point value using the specified precision,
 // which is expected to be 32 or 64bit, to Writer w.
 func printFloat(w io.Writer, val float64, precision int) {
@@ -193,6 +655,27 @@ func printComplex(w io.Writer, c complex128, floatPrecision int) {
 	w.Write(closeParenBytes)
 }
 
+// pointerOrdinals maps pointer addresses to the ordinals which are output in
+// their place when the PointerOrdinals option is set.  Ordinals are assigned
+// in the order the addresses are first seen, starting at 1.
+type pointerOrdinals map[uintptr]int
+
+// printPtr outputs the pointer address p to Writer w, either as its ordinal
+// from ordinals, such as &1, or as hexadecimal if ordinals is nil.
+func printPtr(w io.Writer, p uintptr, ordinals pointerOrdinals) {
+	if ordinals == nil {
+		printHexPtr(w, p)
+		return
+	}
+	n, ok := ordinals[p]
+	if !ok {
+		n = len(ordinals) + 1
+		ordinals[p] = n
+	}
+	w.Write(ampersandBytes)
+	w.Write([]byte(strconv.Itoa(n)))
+}
+
 // printHexPtr outputs a uintptr formatted as hexadecimal with a leading '0x'
 // prefix to Writer w.
 func printHexPtr(w io.Writer, p uintptr) {
@@ -227,6 +710,89 @@ func printHexPtr(w io.Writer, p uintptr) {
 	w.Write(buf)
 }
 
+// printLeaf outputs v to Writer w if it is a bool, number, uintptr, unsafe
+// pointer, channel or func, reporting whether it was.  Integers are output in
+// hexadecimal if hex is set.
+func printLeaf(w io.Writer, cfg *Config, colors ColorScheme, v reflect.Value, hex bool) bool {
+	switch v.Kind() {
+	case reflect.Bool:
+		setColor(w, colors.Number)
+		printBool(w, v.Bool())
+		resetColor(w, colors.Number)
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		setColor(w, colors.Number)
+		if hex {
+			printHexInt(w, v.Int())
+		} else {
+			printInt(w, v.Int(), 10)
+		}
+		resetColor(w, colors.Number)
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		setColor(w, colors.Number)
+		if hex {
+			printHexUint(w, v.Uint())
+		} else {
+			printUint(w, v.Uint(), 10)
+		}
+		resetColor(w, colors.Number)
+
+	case reflect.Float32:
+		setColor(w, colors.Number)
+		printFloat(w, v.Float(), 32)
+		resetColor(w, colors.Number)
+
+	case reflect.Float64:
+		setColor(w, colors.Number)
+		printFloat(w, v.Float(), 64)
+		resetColor(w, colors.Number)
+
+	case reflect.Complex64:
+		setColor(w, colors.Number)
+		printComplex(w, v.Complex(), 32)
+		resetColor(w, colors.Number)
+
+	case reflect.Complex128:
+		setColor(w, colors.Number)
+		printComplex(w, v.Complex(), 64)
+		resetColor(w, colors.Number)
+
+	case reflect.Uintptr:
+		setColor(w, colors.Address)
+		printHexPtr(w, uintptr(v.Uint()))
+		resetColor(w, colors.Address)
+
+	case reflect.UnsafePointer, reflect.Chan:
+		setColor(w, colors.Address)
+		printHexPtr(w, v.Pointer())
+		resetColor(w, colors.Address)
+
+	case reflect.Func:
+		if cfg.FuncSymbols {
+			fn := runtime.FuncForPC(v.Pointer())
+			if fn != nil {
+				name := fn.Name()
+				file, line := fn.FileLine(v.Pointer())
+				w.Write([]byte(filepath.Base(name)))
+				w.Write([]byte("["))
+				w.Write([]byte(filepath.Base(file)))
+				w.Write([]byte(":"))
+				printInt(w, int64(line), 10)
+				w.Write([]byte("]"))
+			}
+		} else {
+			setColor(w, colors.Address)
+			printHexPtr(w, v.Pointer())
+			resetColor(w, colors.Address)
+		}
+
+	default:
+		return false
+	}
+	return true
+}
+
 // valuesSorter implements sort.Interface to allow a slice of reflect.Value
 // elements to be sorted.
 type valuesSorter struct {
@@ -247,7 +813,7 @@ func newValuesSorter(values []reflect.Value, cfg *Config) sort.Interface {
 		vs.strings = make([]string, len(values))
 		for i := range vs.values {
 			b := bytes.Buffer{}
-			if !handleMethods(cfg, &b, vs.values[i]) {
+			if !handleMethods(cfg, &b, vs.values[i], Context{Config: cfg, Inline: true}) {
 				vs.strings = nil
 				break
 			}
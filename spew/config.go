@@ -18,9 +18,12 @@ package spew
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
+	"regexp"
 )
 
 // Config houses the configuration options used by spew to format and
@@ -51,6 +54,40 @@ type Config struct {
 	// nested data structures.
 	MaxDepth int
 
+	// DepthOverrides specifies limits which replace MaxDepth for the values
+	// they match and the values within them, such as to expand *Node values
+	// only 2 levels but show Config values fully.  The first which matches
+	// a value applies, with the levels counted from the value.  An override
+	// already in effect for a value containing it is not applied again, so
+	// that recursive types stay within their limit.
+	DepthOverrides []DepthOverride
+
+	// MaxElements controls the maximum number of elements of arrays, slices
+	// and maps to output, other than byte arrays and slices.  The rest are
+	// replaced by a marker such as ... (9990 more).  The default, 0, means
+	// there is no limit.
+	MaxElements int
+
+	// MaxStringLen controls the maximum number of bytes of strings, byte
+	// arrays and byte slices to output.  The rest are replaced by a marker
+	// such as ... (9990 more).  Strings are only cut at rune boundaries.  The
+	// default, 0, means there is no limit.
+	MaxStringLen int
+
+	// ElideMiddle specifies whether MaxElements and MaxStringLen output both
+	// the first and the last elements or bytes, with the marker between
+	// them, rather than only the first.
+	ElideMiddle bool
+
+	// MaxBytes controls the approximate maximum number of bytes of output of
+	// Fdump, Sdump and the Formatter.  Once it is exceeded, no further values
+	// are output, open braces are closed and the marker
+	// <truncated after N bytes> is appended.  The budget is shared by all of
+	// the arguments to Fdump and Sdump, but fmt invokes the Formatter for each
+	// operand on its own, so each operand of Printf and friends has a budget
+	// of its own.  The default, 0, means there is no limit.
+	MaxBytes int
+
 	// DisableMethods specifies whether or not error and Stringer interfaces are
 	// invoked for types that implement them.
 	DisableMethods bool
@@ -73,6 +110,22 @@ type Config struct {
 	// pointer addresses. This is useful when diffing data structures in tests.
 	DisablePointerAddresses bool
 
+	// PointerOrdinals specifies whether to replace pointer addresses with
+	// ordinals, such as &1 and &2, which are assigned in the order the
+	// addresses are first seen.  Unlike DisablePointerAddresses, this keeps
+	// the output stable across runs while still showing which pointers point
+	// to the same value.  Ordinals are shared by all of the arguments to a
+	// single Dump call.  It has no effect if DisablePointerAddresses is set.
+	PointerOrdinals bool
+
+	// DedupeShared specifies whether to only show the value a
+	// pointer points to the first time the pointer is reached.  Later
+	// references to it are shown as <see PATH>, where PATH is the path the
+	// value was shown at, such as .Items[3].Owner.  Otherwise, values which
+	// are shared by multiple pointers are shown once for each of them, and
+	// only circular references are detected.
+	DedupeShared bool
+
 	// DisableCapacities specifies whether to disable the printing of capacities
 	// for arrays, slices, maps and channels. This is useful when diffing
 	// data structures in tests.
@@ -91,7 +144,8 @@ type Config struct {
 
 	// TrailingCommas specifies whether to always include a trailing comma,
 	// Go-style. This is useful to avoid false positives when diffing data
-	// structures in tests.
+	// structures in tests.  The Formatter only includes it when Commas is
+	// also set, since it separates elements with spaces otherwise.
 	TrailingCommas bool
 
 	// DisableUnexported specifies whether to disable the unexported fields of
@@ -136,6 +190,137 @@ type Config struct {
 	// be spewed to strings and sorted by those strings.  This is only
 	// considered if SortKeys is true.
 	SpewKeys bool
+
+	// OmitNilFields specifies whether to skip struct fields and map entries
+	// whose values are nil pointers, interfaces, maps, slices, channels or
+	// funcs.  Nil elements of arrays and slices are still output.
+	OmitNilFields bool
+
+	// OmitZeroFields specifies whether to skip struct fields and map entries
+	// whose values are the zero value for their type, which includes nil.
+	// Zero elements of arrays and slices are still output.
+	OmitZeroFields bool
+
+	// Color specifies whether to colorize types, field names, strings,
+	// numbers, nil, pointer addresses and markers with ANSI escape sequences
+	// in Dump and Formatter output.  Colors are never used when the NO_COLOR
+	// environment variable is set to a non-empty value.
+	Color bool
+
+	// ColorScheme specifies the escape sequences to use when Color is set.
+	// The default, nil, means DefaultColorScheme is used.
+	ColorScheme *ColorScheme
+
+	// IsTerminal is an optional hint which reports whether w is a terminal.
+	// When it is set and Color is set, output to writers for which it returns
+	// false is not colorized.  Spew can not know the destination of values
+	// formatted by NewFormatter or the Sprint and Errorf family of functions,
+	// so w is nil for those.
+	IsTerminal func(w io.Writer) bool
+
+	// Redact specifies patterns for the names of struct fields and the string
+	// keys of maps whose values should not be output, such as
+	// regexp.MustCompile("(?i)password|token|secret").  Each pattern is
+	// matched against both the name and the path of the value, such as
+	// .Spec.Items[3].Token or ["token"].  Redacted values are output as
+	// <redacted len=N>, where the length is omitted for values without one.
+	// Every output format applies it, using the same paths as Dump.  Diff
+	// reports redacted values which differ as <redacted> on both sides.
+	Redact []*regexp.Regexp
+
+	// RedactValues specifies patterns for string values which should not be
+	// output, wherever they are found.  They are output as <redacted len=N>
+	// by every output format.  Redacted map keys are output as
+	// <redacted len=N #I> by the JSON, YAML, HTML and Go syntax output, where
+	// I is the index of the entry, so that they stay distinct.
+	RedactValues []*regexp.Regexp
+
+	// Paths specifies paths to the parts of each value which Dump should
+	// output, such as .Spec.Containers[*].Env or .Labels["app"], in the
+	// language of Select.  Every value selected is output after its full
+	// path, such as .Spec.Containers[0].Env.  Paths which can not be parsed
+	// or do not select a value are output with the reason in place of a
+	// value.  The default, nil, means each value is output whole.  The
+	// Formatter always outputs whole values.
+	Paths []string
+
+	// IncludeFields specifies glob patterns for the paths of the struct
+	// fields and map entries to output, such as *.Spec.Containers or
+	// **.Env.  Patterns are split at dots, where ** matches any number of
+	// names and other parts match a single name with path.Match syntax.
+	// They are matched against the names of fields and string map keys
+	// down from the top level value, whose name is empty, so array and
+	// slice indexes and other map keys are not part of them.  Fields and
+	// entries which do not match are left out unless values within them
+	// could.  The default, nil, means everything is output.
+	IncludeFields []string
+
+	// ExcludeFields specifies glob patterns, like those of IncludeFields,
+	// for the paths of struct fields and map entries to leave out, such as
+	// *.ObjectMeta.ManagedFields or **.XXX_*.  It takes precedence over
+	// IncludeFields.
+	ExcludeFields []string
+
+	// formatters holds the custom formatters added with RegisterFormatter.
+	// Copies of a Config share it until one of them registers a formatter,
+	// so formattersOwner records the Config it was made for, and the others
+	// make their own copy before changing it.
+	formatters      map[reflect.Type]FormatterFunc
+	formattersOwner *Config
+}
+
+// DepthOverride replaces the MaxDepth option for the values of Type at the
+// paths which match Path, and the values within them.  Path is a glob pattern
+// like those of the IncludeFields option.  Either may be left unset to match
+// any value.
+type DepthOverride struct {
+	Type reflect.Type
+	Path string
+
+	// MaxDepth is the number of levels to descend into the value, where 0
+	// means there is no limit.
+	MaxDepth int
+}
+
+// Context describes where a value is being output to a custom formatter or
+// SpewDumper.
+type Context struct {
+	// Config is the configuration in use.
+	Config *Config
+
+	// Depth is the nesting level of the value, which is 0 at the top level.
+	Depth int
+
+	// Indent is the indentation of the current nesting level in Dump output,
+	// which should begin every line written after the first.  It is always
+	// empty for the Formatter.
+	Indent string
+
+	// Inline is set when the value is being output by the Formatter, which
+	// expects it to be written on a single line.
+	Inline bool
+
+	// done is the Done channel of the context.Context passed to
+	// FdumpContext, if any.
+	done <-chan struct{}
+}
+
+// FormatterFunc is a custom formatter which writes v to w in place of the
+// value spew would normally output for its type.
+type FormatterFunc func(w io.Writer, v reflect.Value, ctx Context)
+
+// SpewDumper is implemented by types which control how they are output by
+// Dump and the Formatter.  It is checked for before the error and Stringer
+// interfaces, and is subject to the same DisableMethods and
+// DisablePointerMethods options, but recursion never continues into the
+// value afterwards, regardless of ContinueOnMethod.
+//
+// SpewDump writes the value to w.  Type information and lengths have already
+// been output by then.  In Dump output, every line after the first should
+// begin with ctx.Indent so that the value nests correctly, while the
+// Formatter, which sets ctx.Inline, expects a single line.
+type SpewDumper interface {
+	SpewDump(w io.Writer, ctx Context)
 }
 
 // Default holds the configuration of the top-level functions.
@@ -168,7 +353,7 @@ var CleanConfig = Config{
 //
 //	fmt.Errorf(format, c.NewFormatter(a), c.NewFormatter(b))
 func (c *Config) Errorf(format string, a ...any) (err error) {
-	return fmt.Errorf(format, c.convertArgs(a)...)
+	return fmt.Errorf(format, c.convertArgs(nil, a)...)
 }
 
 // Fprint is a wrapper for fmt.Fprint that treats each argument as if it were
@@ -180,7 +365,7 @@ func (c *Config) Errorf(format string, a ...any) (err error) {
 //
 //	fmt.Fprint(w, c.NewFormatter(a), c.NewFormatter(b))
 func (c *Config) Fprint(w io.Writer, a ...any) (n int, err error) {
-	return fmt.Fprint(w, c.convertArgs(a)...)
+	return fmt.Fprint(w, c.convertArgs(w, a)...)
 }
 
 // Fprintf is a wrapper for fmt.Fprintf that treats each argument as if it were
@@ -192,7 +377,7 @@ func (c *Config) Fprint(w io.Writer, a ...any) (n int, err error) {
 //
 //	fmt.Fprintf(w, format, c.NewFormatter(a), c.NewFormatter(b))
 func (c *Config) Fprintf(w io.Writer, format string, a ...any) (n int, err error) {
-	return fmt.Fprintf(w, format, c.convertArgs(a)...)
+	return fmt.Fprintf(w, format, c.convertArgs(w, a)...)
 }
 
 // Fprintln is a wrapper for fmt.Fprintln that treats each argument as if it
@@ -203,7 +388,7 @@ func (c *Config) Fprintf(w io.Writer, format string, a ...any) (n int, err error
 //
 //	fmt.Fprintln(w, c.NewFormatter(a), c.NewFormatter(b))
 func (c *Config) Fprintln(w io.Writer, a ...any) (n int, err error) {
-	return fmt.Fprintln(w, c.convertArgs(a)...)
+	return fmt.Fprintln(w, c.convertArgs(w, a)...)
 }
 
 // Print is a wrapper for fmt.Print that treats each argument as if it were
@@ -215,7 +400,7 @@ func (c *Config) Fprintln(w io.Writer, a ...any) (n int, err error) {
 //
 //	fmt.Print(c.NewFormatter(a), c.NewFormatter(b))
 func (c *Config) Print(a ...any) (n int, err error) {
-	return fmt.Print(c.convertArgs(a)...)
+	return fmt.Print(c.convertArgs(os.Stdout, a)...)
 }
 
 // Printf is a wrapper for fmt.Printf that treats each argument as if it were
@@ -227,7 +412,7 @@ func (c *Config) Print(a ...any) (n int, err error) {
 //
 //	fmt.Printf(format, c.NewFormatter(a), c.NewFormatter(b))
 func (c *Config) Printf(format string, a ...any) (n int, err error) {
-	return fmt.Printf(format, c.convertArgs(a)...)
+	return fmt.Printf(format, c.convertArgs(os.Stdout, a)...)
 }
 
 // Println is a wrapper for fmt.Println that treats each argument as if it were
@@ -239,7 +424,7 @@ func (c *Config) Printf(format string, a ...any) (n int, err error) {
 //
 //	fmt.Println(c.NewFormatter(a), c.NewFormatter(b))
 func (c *Config) Println(a ...any) (n int, err error) {
-	return fmt.Println(c.convertArgs(a)...)
+	return fmt.Println(c.convertArgs(os.Stdout, a)...)
 }
 
 // Sprint is a wrapper for fmt.Sprint that treats each argument as if it were
@@ -250,7 +435,7 @@ func (c *Config) Println(a ...any) (n int, err error) {
 //
 //	fmt.Sprint(c.NewFormatter(a), c.NewFormatter(b))
 func (c *Config) Sprint(a ...any) string {
-	return fmt.Sprint(c.convertArgs(a)...)
+	return fmt.Sprint(c.convertArgs(nil, a)...)
 }
 
 // Sprintf is a wrapper for fmt.Sprintf that treats each argument as if it were
@@ -261,7 +446,7 @@ func (c *Config) Sprint(a ...any) string {
 //
 //	fmt.Sprintf(format, c.NewFormatter(a), c.NewFormatter(b))
 func (c *Config) Sprintf(format string, a ...any) string {
-	return fmt.Sprintf(format, c.convertArgs(a)...)
+	return fmt.Sprintf(format, c.convertArgs(nil, a)...)
 }
 
 // Sprintln is a wrapper for fmt.Sprintln that treats each argument as if it
@@ -272,7 +457,32 @@ func (c *Config) Sprintf(format string, a ...any) string {
 //
 //	fmt.Sprintln(c.NewFormatter(a), c.NewFormatter(b))
 func (c *Config) Sprintln(a ...any) string {
-	return fmt.Sprintln(c.convertArgs(a)...)
+	return fmt.Sprintln(c.convertArgs(nil, a)...)
+}
+
+// RegisterFormatter overrides how values of type t are output by Dump and the
+// Formatter, which is useful for types you don't own and so can't add an
+// error or Stringer interface to.  As with those interfaces, type
+// information and lengths are still output before calling fn, and fn takes
+// precedence over them.  Pointers are followed as usual before the type is
+// looked up, so register the type pointed to rather than the pointer type.
+// Passing a nil fn removes the formatter for t.  Formatters registered with
+// a copy of c, such as one made by c2 := *c, do not affect c, and vice versa.
+//
+// RegisterFormatter is not safe to call concurrently with any output using c.
+func (c *Config) RegisterFormatter(t reflect.Type, fn FormatterFunc) {
+	if c.formattersOwner != c {
+		formatters := make(map[reflect.Type]FormatterFunc, len(c.formatters))
+		for k, v := range c.formatters {
+			formatters[k] = v
+		}
+		c.formatters, c.formattersOwner = formatters, c
+	}
+	if fn == nil {
+		delete(c.formatters, t)
+		return
+	}
+	c.formatters[t] = fn
 }
 
 // NewFormatter returns a custom formatter that satisfies the fmt.Formatter
@@ -291,7 +501,7 @@ func (c *Config) Sprintln(a ...any) string {
 // use of the custom formatter by calling one of the convenience functions such as
 // c.Printf, c.Println, or c.Printf.
 func (c *Config) NewFormatter(v any) fmt.Formatter {
-	return newFormatter(c, v)
+	return newFormatter(c, nil, v)
 }
 
 // Fdump formats and displays the passed arguments to io.Writer w.  It formats
@@ -300,6 +510,21 @@ func (c *Config) Fdump(w io.Writer, a ...any) {
 	fdump(c, w, a...)
 }
 
+// FdumpErr formats and displays the passed arguments to io.Writer w exactly the
+// same as Fdump.  It returns the number of bytes written and any write error
+// encountered, which stops the output.
+func (c *Config) FdumpErr(w io.Writer, a ...any) (n int, err error) {
+	return fdump(c, w, a...)
+}
+
+// FdumpContext formats and displays the passed arguments to io.Writer w exactly
+// the same as Fdump, stopping early once ctx is done.  See the package level
+// FdumpContext for details.
+func (c *Config) FdumpContext(ctx context.Context, w io.Writer, a ...any) error {
+	_, err := fdumpContext(ctx, c, w, a...)
+	return err
+}
+
 // Dump displays the passed parameters to standard out with newlines, customizable
 // indentation, and additional debug information such as complete types and all
 // pointer addresses used to indirect to the final value.  It provides the
@@ -333,13 +558,91 @@ func (c *Config) Sdump(a ...any) string {
 	return buf.String()
 }
 
+// Diff returns a report of the differences between a and b, one per line, in
+// the form:
+//
+//	.Spec.Items[3].Name: "a" -> "b"
+//
+// Both values are walked the same way Dump walks them, so pointers are
+// followed, circular data structures are detected and handled properly, and
+// the SortKeys, DisableUnexported and method invocation options of c are
+// honored.  An empty string means no differences were found.
+func (c *Config) Diff(a, b any) string {
+	return sdiff(c, a, b)
+}
+
+// Fgo writes the passed argument to io.Writer w as Go source.  It formats
+// exactly the same as Sgo.
+func (c *Config) Fgo(w io.Writer, a any) {
+	w.Write([]byte(sgo(c, a)))
+}
+
+// Sgo returns the passed argument rendered as a Go expression which evaluates
+// to an equivalent value.  Structs, arrays, slices and maps are rendered as
+// composite literals and pointers as &T{...}.  Pointers which are reached more
+// than once, including through circular references, are bound to helper
+// variables inside a function literal so that the output still compiles.
+//
+// The Indent, DisableUnexported and SortKeys options of c are honored.  See
+// the package-level Sgo for more details.
+func (c *Config) Sgo(a any) string {
+	return sgo(c, a)
+}
+
+// Fjson formats and displays the passed argument to io.Writer w as JSON.  It
+// formats exactly the same as Sjson.
+func (c *Config) Fjson(w io.Writer, a any) {
+	fjson(c, w, a)
+}
+
+// Sjson returns a string with the passed argument formatted as JSON.  Values
+// are walked the same way Dump walks them, and carry "$type", "$addrs", "len"
+// and "cap" metadata subject to the options of c.  Circular references are
+// output as {"$ref": "#/path"}.  See the package-level Sjson for more
+// details.
+func (c *Config) Sjson(a any) string {
+	var buf bytes.Buffer
+	fjson(c, &buf, a)
+	return buf.String()
+}
+
+// Fyaml formats and displays the passed argument to io.Writer w as a YAML
+// document.  It formats exactly the same as Syaml.
+func (c *Config) Fyaml(w io.Writer, a any) {
+	fyaml(c, w, a)
+}
+
+// Syaml returns a string with the passed argument formatted as a YAML
+// document.  Pointers which are reached more than once are output as &idNNN
+// anchors and *idNNN aliases.  See the package-level Syaml for more details.
+func (c *Config) Syaml(a any) string {
+	var buf bytes.Buffer
+	fyaml(c, &buf, a)
+	return buf.String()
+}
+
+// Fhtml formats and displays the passed argument to io.Writer w as a
+// standalone HTML page with collapsible nodes.  See the package-level Fhtml
+// for more details.
+func (c *Config) Fhtml(w io.Writer, a any) {
+	fhtml(c, w, a)
+}
+
+// Fdot formats and displays the passed argument to io.Writer w as a Graphviz
+// DOT graph of the values it holds and the pointers between them.  See the
+// package-level Fdot for more details.
+func (c *Config) Fdot(w io.Writer, a any) {
+	fdot(c, w, a)
+}
+
 // convertArgs accepts a slice of arguments and returns a slice of the same
 // length with each argument converted to a spew Formatter interface using
-// the Config associated with s.
-func (c *Config) convertArgs(args []any) (formatters []any) {
+// the Config associated with s.  The output is destined for w, which is nil if
+// it isn't known.
+func (c *Config) convertArgs(w io.Writer, args []any) (formatters []any) {
 	formatters = make([]any, len(args))
 	for index, arg := range args {
-		formatters[index] = newFormatter(c, arg)
+		formatters[index] = newFormatter(c, w, arg)
 	}
 	return formatters
 }
@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// blocker is used to test that FdumpContext stops waiting for a String
+// method which does not return.
+type blocker chan struct{}
+
+func (b blocker) String() string {
+	<-b
+	return "unblocked"
+}
+
+// contextTest is used to describe a test to be performed against
+// FdumpContext.
+type contextTest struct {
+	line string // use line() to fill this
+	ctx  func() (context.Context, context.CancelFunc)
+	in   any
+	want string
+	err  error
+}
+
+// TestFdumpContext executes all of the tests described by contextTests.
+func TestFdumpContext(t *testing.T) {
+	cfg := &spew.Config{Indent: " ", DisableCapacities: true}
+
+	background := func() (context.Context, context.CancelFunc) {
+		return context.WithCancel(context.Background())
+	}
+	canceled := func() (context.Context, context.CancelFunc) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return ctx, cancel
+	}
+	timeout := func() (context.Context, context.CancelFunc) {
+		return context.WithTimeout(context.Background(), 10*time.Millisecond)
+	}
+
+	b := make(blocker)
+	defer close(b)
+
+	tests := []contextTest{
+		{line(), background, []int{1, 2}, "([]int) (len=2) {\n (int) 1,\n (int) 2\n}\n", nil},
+		{line(), background, []any{panicer(1)}, "([]interface {}) (len=1) {\n" +
+			" (spew_test.panicer) (PANIC=test panic)1\n" +
+			"}\n", nil},
+		{line(), canceled, []int{1, 2}, "<truncated after 0 bytes: context canceled>\n",
+			context.Canceled},
+		{line(), timeout, []any{1, b, 3}, "([]interface {}) (len=3) {\n" +
+			" (int) 1,\n" +
+			" (spew_test.blocker) \n" +
+			"}\n" +
+			"<truncated after 61 bytes: context deadline exceeded>\n", context.DeadlineExceeded},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		ctx, cancel := test.ctx()
+		var buf bytes.Buffer
+		err := cfg.FdumpContext(ctx, &buf, test.in)
+		cancel()
+		if s := buf.String(); s != test.want || err != test.err {
+			t.Errorf("testcase on line %s:\n got: %v %s\nwant: %v %s",
+				test.line, err, s, test.err, test.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	want := spew.Sdump(1, "a")
+	if err := spew.FdumpContext(context.Background(), &buf, 1, "a"); buf.String() != want || err != nil {
+		t.Errorf("FdumpContext:\n got: %v %s\nwant: %v %s", err, buf.String(), nil, want)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// dedupeGraph is used to test values shared by several pointers.
+type dedupeGraph struct {
+	Deps  []*ordNode
+	Owner *ordNode
+}
+
+// dedupeTree is used to test a value shared by pointers at different depths.
+type dedupeTree struct {
+	Next *ordNode
+	Kids []*ordNode
+}
+
+// TestDedupeShared ensures values shared by several pointers are only dumped
+// the first time, while circular references are still detected.
+func TestDedupeShared(t *testing.T) {
+	cfg := &spew.Config{Indent: " ", DisablePointerAddresses: true,
+		DisableCapacities: true, SortKeys: true, DedupeShared: true}
+
+	leaf := &ordNode{Name: "l"}
+	shared := &ordNode{Name: "s", A: leaf}
+	cyclic := &ordNode{Name: "c"}
+	cyclic.A = cyclic
+	cyclic.B = leaf
+
+	tests := []struct {
+		line string // use line() to fill this
+		got  string
+		want string
+	}{
+		{line(), cfg.Sdump(dedupeGraph{Deps: []*ordNode{shared, shared, leaf}, Owner: shared}), "(spew_test.dedupeGraph) {\n" +
+			" Deps: ([]*spew_test.ordNode) (len=3) {\n" +
+			"  (*spew_test.ordNode)({\n" +
+			"   Name: (string) (len=1) \"s\",\n" +
+			"   A: (*spew_test.ordNode)({\n" +
+			"    Name: (string) (len=1) \"l\",\n" +
+			"    A: (*spew_test.ordNode)(<nil>),\n" +
+			"    B: (*spew_test.ordNode)(<nil>)\n" +
+			"   }),\n" +
+			"   B: (*spew_test.ordNode)(<nil>)\n" +
+			"  }),\n" +
+			"  (*spew_test.ordNode)(<see .Deps[0]>),\n" +
+			"  (*spew_test.ordNode)(<see .Deps[0].A>)\n" +
+			" },\n" +
+			" Owner: (*spew_test.ordNode)(<see .Deps[0]>)\n" +
+			"}\n"},
+		{line(), cfg.Sdump(map[string]*ordNode{"a": leaf, "b": leaf}), "(map[string]*spew_test.ordNode) (len=2) {\n" +
+			" (string) (len=1) \"a\": (*spew_test.ordNode)({\n" +
+			"  Name: (string) (len=1) \"l\",\n" +
+			"  A: (*spew_test.ordNode)(<nil>),\n" +
+			"  B: (*spew_test.ordNode)(<nil>)\n" +
+			" }),\n" +
+			" (string) (len=1) \"b\": (*spew_test.ordNode)(<see [\"a\"]>)\n" +
+			"}\n"},
+		{line(), cfg.Sdump(cyclic), "(*spew_test.ordNode)({\n" +
+			" Name: (string) (len=1) \"c\",\n" +
+			" A: (*spew_test.ordNode)(<already shown>),\n" +
+			" B: (*spew_test.ordNode)({\n" +
+			"  Name: (string) (len=1) \"l\",\n" +
+			"  A: (*spew_test.ordNode)(<nil>),\n" +
+			"  B: (*spew_test.ordNode)(<nil>)\n" +
+			" })\n" +
+			"})\n"},
+		{line(), cfg.Sdump(&dedupeTree{Next: leaf, Kids: []*ordNode{leaf, leaf}}), "(*spew_test.dedupeTree)({\n" +
+			" Next: (*spew_test.ordNode)({\n" +
+			"  Name: (string) (len=1) \"l\",\n" +
+			"  A: (*spew_test.ordNode)(<nil>),\n" +
+			"  B: (*spew_test.ordNode)(<nil>)\n" +
+			" }),\n" +
+			" Kids: ([]*spew_test.ordNode) (len=2) {\n" +
+			"  (*spew_test.ordNode)(<see .Next>),\n" +
+			"  (*spew_test.ordNode)(<see .Next>)\n" +
+			" }\n" +
+			"})\n"},
+		{line(), cfg.Sdump([]*ordNode{leaf}, leaf), "([]*spew_test.ordNode) (len=1) {\n" +
+			" (*spew_test.ordNode)({\n" +
+			"  Name: (string) (len=1) \"l\",\n" +
+			"  A: (*spew_test.ordNode)(<nil>),\n" +
+			"  B: (*spew_test.ordNode)(<nil>)\n" +
+			" })\n" +
+			"}\n" +
+			"(*spew_test.ordNode)({\n" +
+			" Name: (string) (len=1) \"l\",\n" +
+			" A: (*spew_test.ordNode)(<nil>),\n" +
+			" B: (*spew_test.ordNode)(<nil>)\n" +
+			"})\n"},
+	}
+
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, test.got, test.want)
+		}
+	}
+}
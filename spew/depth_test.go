@@ -0,0 +1,76 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+type depthNode struct {
+	Next *depthNode
+	Conf depthConfig
+}
+
+type depthConfig struct {
+	Opts map[string][]int
+}
+
+// depthTest is used to describe a test to be performed against the
+// DepthOverrides option.
+type depthTest struct {
+	line      string // use line() to fill this
+	maxDepth  int
+	overrides []spew.DepthOverride
+	in        any
+	want      string
+}
+
+// TestDepthOverrides executes all of the tests described by depthTests.
+func TestDepthOverrides(t *testing.T) {
+	conf := depthConfig{Opts: map[string][]int{"a": {1}}}
+	list := &depthNode{Conf: conf, Next: &depthNode{Next: &depthNode{}}}
+	nodeType := reflect.TypeOf(list)
+	confType := reflect.TypeOf(conf)
+
+	tests := []depthTest{
+		{line(), 0, nil, list,
+			"<*>{Next:<*>{Next:<*>{Next:<nil> Conf:{Opts:<nil>}} Conf:{Opts:<nil>}} Conf:{Opts:map[a:[1]]}}"},
+		{line(), 0, []spew.DepthOverride{{Type: nodeType, MaxDepth: 2}}, list,
+			"<*>{Next:<*>{Next:<*>{<max>} Conf:{<max>}} Conf:{Opts:map[<max>]}}"},
+		{line(), 0, []spew.DepthOverride{{Type: nodeType, MaxDepth: 2}, {Type: confType}}, list,
+			"<*>{Next:<*>{Next:<*>{<max>} Conf:{Opts:<nil>}} Conf:{Opts:map[a:[1]]}}"},
+		{line(), 1, []spew.DepthOverride{{Type: confType}}, list,
+			"<*>{Next:<*>{<max>} Conf:{Opts:map[a:[1]]}}"},
+		{line(), 1, []spew.DepthOverride{{Path: "*.Conf", MaxDepth: 1}}, list,
+			"<*>{Next:<*>{<max>} Conf:{Opts:map[<max>]}}"},
+		{line(), 0, []spew.DepthOverride{{Path: "**.Opts", MaxDepth: 1}}, conf,
+			"{Opts:map[a:[<max>]]}"},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		cfg := &spew.Config{DisablePointerAddresses: true, MaxDepth: test.maxDepth,
+			DepthOverrides: test.overrides}
+		s := cfg.Sprintf("%+v", test.in)
+		if s != test.want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, s, test.want)
+		}
+	}
+}
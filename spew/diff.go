@@ -0,0 +1,357 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// pointerPair identifies the pair of pointers being compared at some point in
+// a diff.  It is used to detect circular references on both sides at once.
+type pointerPair struct {
+	a, b uintptr
+}
+
+// diffState contains information about the state of a diff operation.
+type diffState struct {
+	buf      bytes.Buffer
+	depth    int
+	pointers map[pointerPair]int
+	cfg      *Config
+	fmtCfg   Config
+}
+
+// unpackValue returns values inside of non-nil interfaces when possible.
+// This is useful for data types like structs, arrays, slices, and maps which
+// can contain varying types packed inside an interface.
+func (d *diffState) unpackValue(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// report records a single difference at path.  The values are rendered
+// inline, with types if showTypes is set.
+func (d *diffState) report(path string, a, b reflect.Value, showTypes bool) {
+	if path != "" {
+		d.buf.WriteString(path)
+		d.buf.Write(colonSpaceBytes)
+	}
+	d.sprint(a, showTypes)
+	d.buf.Write(diffArrowBytes)
+	d.sprint(b, showTypes)
+	d.buf.Write(newlineBytes)
+}
+
+// sprint renders v inline to the diff buffer in the same way as the
+// Formatter.  Strings are always quoted so that differences in whitespace are
+// visible.
+func (d *diffState) sprint(v reflect.Value, showTypes bool) {
+	if !v.IsValid() {
+		d.buf.Write(missingBytes)
+		return
+	}
+	flags := ""
+	if showTypes {
+		flags = "#"
+	}
+	d.buf.WriteString(sprintValue(&d.fmtCfg, v, flags))
+}
+
+// methodString returns the result of invoking the error or Stringer interface
+// on v, if there is one and it is not configured to continue on to the
+// underlying value.
+func (d *diffState) methodString(v reflect.Value) (string, bool) {
+	var buf bytes.Buffer
+	if handled := handleMethods(&d.fmtCfg, &buf, v, Context{Config: d.cfg, Depth: d.depth, Inline: true}); handled {
+		return buf.String(), true
+	}
+	return "", false
+}
+
+// diffPtr handles comparing pointers by indirecting both sides and detecting
+// circular references.
+func (d *diffState) diffPtr(path string, a, b reflect.Value) {
+	if a.IsNil() || b.IsNil() {
+		if a.IsNil() != b.IsNil() {
+			d.report(path, a, b, false)
+		}
+		return
+	}
+
+	// Two pointers to the same address are trivially equal.
+	key := pointerPair{a.Pointer(), b.Pointer()}
+	if key.a == key.b {
+		return
+	}
+
+	// Remove pointers at or below the current depth from map used to detect
+	// circular refs.
+	for k, depth := range d.pointers {
+		if depth >= d.depth {
+			delete(d.pointers, k)
+		}
+	}
+
+	// The same pair has already been compared further up, so descending
+	// again would never terminate.
+	if pd, ok := d.pointers[key]; ok && pd < d.depth {
+		return
+	}
+	d.pointers[key] = d.depth
+
+	d.diff(path, a.Elem(), b.Elem())
+}
+
+// diffSlice handles comparing arrays and slices element by element.  Elements
+// which only exist on one side are reported as missing on the other.
+func (d *diffState) diffSlice(path string, a, b reflect.Value) {
+	numEntries := a.Len()
+	if b.Len() > numEntries {
+		numEntries = b.Len()
+	}
+	for i := 0; i < numEntries; i++ {
+		elemPath := path + "[" + strconv.Itoa(i) + "]"
+		var ea, eb reflect.Value
+		if i < a.Len() {
+			ea = d.unpackValue(a.Index(i))
+		}
+		if i < b.Len() {
+			eb = d.unpackValue(b.Index(i))
+		}
+		if !ea.IsValid() || !eb.IsValid() {
+			d.report(elemPath, ea, eb, false)
+			continue
+		}
+		d.diff(elemPath, ea, eb)
+	}
+}
+
+// diffRedacted compares two values which must not be shown, and reports a
+// single difference at path with both of them redacted if they differ at all.
+// Lengths are left out of the markers, since they may differ too.
+func (d *diffState) diffRedacted(path string, a, b reflect.Value) {
+	n := d.buf.Len()
+	d.diff(path, a, b)
+	if d.buf.Len() == n {
+		return
+	}
+	d.buf.Truncate(n)
+	d.buf.WriteString(path)
+	d.buf.Write(colonSpaceBytes)
+	d.redacted(a)
+	d.buf.Write(diffArrowBytes)
+	d.redacted(b)
+	d.buf.Write(newlineBytes)
+}
+
+// redacted writes the marker for the redacted value v to the diff buffer, or
+// the missing marker if there is no value.
+func (d *diffState) redacted(v reflect.Value) {
+	if !v.IsValid() {
+		d.buf.Write(missingBytes)
+		return
+	}
+	d.buf.Write(redactedBytes)
+}
+
+// diffMap handles comparing maps entry by entry.  Keys which only exist on one
+// side are reported as missing on the other.
+func (d *diffState) diffMap(path string, a, b reflect.Value) {
+	keys := a.MapKeys()
+	for _, key := range b.MapKeys() {
+		if !a.MapIndex(key).IsValid() {
+			keys = append(keys, key)
+		}
+	}
+	if d.cfg.SortKeys {
+		sortValues(keys, d.cfg)
+	}
+	for _, key := range keys {
+		entryPath := path + "[" + sprintValue(&d.fmtCfg, d.unpackValue(key), "") + "]"
+
+		ea, eb := a.MapIndex(key), b.MapIndex(key)
+		if uk := d.unpackValue(key); uk.Kind() == reflect.String && redactName(d.cfg, uk.String(), entryPath) {
+			d.diffRedacted(entryPath, d.unpackValue(ea), d.unpackValue(eb))
+			continue
+		}
+		if !ea.IsValid() || !eb.IsValid() {
+			d.report(entryPath, d.unpackValue(ea), d.unpackValue(eb), false)
+			continue
+		}
+		d.diff(entryPath, d.unpackValue(ea), d.unpackValue(eb))
+	}
+}
+
+// diff is the main workhorse for comparing two values.  It walks both values
+// in lock step the same way dump does, and records every leaf which differs
+// along with its path.  It is a recursive function, however circular data
+// structures are detected and handled properly.
+func (d *diffState) diff(path string, a, b reflect.Value) {
+	// Handle invalid reflect values immediately.
+	if !a.IsValid() || !b.IsValid() {
+		if a.IsValid() != b.IsValid() {
+			d.report(path, a, b, true)
+		}
+		return
+	}
+
+	// Values of different types can't be compared any further.
+	if a.Type() != b.Type() {
+		d.report(path, a, b, true)
+		return
+	}
+
+	// Handle pointers specially.
+	kind := a.Kind()
+	if kind == reflect.Ptr {
+		d.diffPtr(path, a, b)
+		return
+	}
+
+	// Compare the Stringer/error output if they exist and the handle methods
+	// flag is enabled.
+	if !d.cfg.DisableMethods && kind != reflect.Interface {
+		sa, handled := d.methodString(a)
+		if handled {
+			sb, _ := d.methodString(b)
+			if sa != sb {
+				d.report(path, a, b, false)
+			}
+			return
+		}
+	}
+
+	equal := true
+	switch kind {
+	case reflect.Bool:
+		equal = a.Bool() == b.Bool()
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		equal = a.Int() == b.Int()
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint, reflect.Uintptr:
+		equal = a.Uint() == b.Uint()
+
+	case reflect.Float32, reflect.Float64:
+		fa, fb := a.Float(), b.Float()
+		equal = fa == fb || (math.IsNaN(fa) && math.IsNaN(fb))
+
+	case reflect.Complex64, reflect.Complex128:
+		equal = a.Complex() == b.Complex()
+
+	case reflect.String:
+		equal = a.String() == b.String()
+
+	case reflect.Interface:
+		// The only time we should get here is for nil interfaces due to
+		// unpackValue calls.
+		equal = a.IsNil() == b.IsNil()
+
+	case reflect.Slice, reflect.Map:
+		// nil slices and maps should be indicated as different than empty
+		// ones.
+		if a.IsNil() || b.IsNil() {
+			equal = a.IsNil() == b.IsNil()
+			break
+		}
+		if a.Pointer() == b.Pointer() && a.Len() == b.Len() {
+			break
+		}
+		d.depth++
+		if kind == reflect.Map {
+			d.diffMap(path, a, b)
+		} else {
+			d.diffSlice(path, a, b)
+		}
+		d.depth--
+
+	case reflect.Array:
+		d.depth++
+		d.diffSlice(path, a, b)
+		d.depth--
+
+	case reflect.Struct:
+		d.depth++
+		vt := a.Type()
+		numFields := a.NumField()
+		for i := 0; i < numFields; i++ {
+			vtf := vt.Field(i)
+			// StructField has an IsExported() method, but only in 1.17+.
+			if d.cfg.DisableUnexported && vtf.PkgPath != "" {
+				continue
+			}
+			// A field is only left out when neither side shows it.
+			fieldA, okA := fieldTag(a, i)
+			_, okB := fieldTag(b, i)
+			if !okA && !okB {
+				continue
+			}
+			fieldPath := path + "." + vtf.Name
+			fa, fb := d.unpackValue(a.Field(i)), d.unpackValue(b.Field(i))
+			if fieldA.redact || redactName(d.cfg, vtf.Name, fieldPath) {
+				d.diffRedacted(fieldPath, fa, fb)
+				continue
+			}
+			d.diff(fieldPath, fa, fb)
+		}
+		d.depth--
+
+	case reflect.UnsafePointer, reflect.Chan, reflect.Func:
+		equal = a.Pointer() == b.Pointer()
+
+	// There were not any other types at the time this code was written, but
+	// fall back to comparing the formatted output in case any new types are
+	// added.
+	default:
+		equal = fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+	}
+
+	if !equal {
+		d.report(path, a, b, false)
+	}
+}
+
+// sdiff is a helper function to consolidate the logic from the various public
+// methods which take varying config states.
+func sdiff(cfg *Config, a, b any) string {
+	d := diffState{cfg: cfg, fmtCfg: *cfg}
+	d.fmtCfg.QuoteStrings = true
+	d.pointers = make(map[pointerPair]int)
+	d.diff("", reflect.ValueOf(a), reflect.ValueOf(b))
+	return d.buf.String()
+}
+
+// Diff returns a report of the differences between a and b, one per line, in
+// the form:
+//
+//	.Spec.Items[3].Name: "a" -> "b"
+//
+// Both values are walked the same way Dump walks them, so pointers are
+// followed, circular data structures are detected and handled properly, and
+// the SortKeys, DisableUnexported and method invocation options of the
+// global Default config are honored.  Values which differ are rendered
+// inline as the Formatter would render them.  An empty string means no
+// differences were found.
+func Diff(a, b any) string {
+	return sdiff(&Default, a, b)
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// diffTest is used to describe a test to be performed against the Diff
+// method.
+type diffTest struct {
+	line string // use line() to fill this
+	cfg  *spew.Config
+	a, b any
+	want string
+}
+
+// diffItem and diffSpec are used to test paths through nested structs.
+type diffItem struct {
+	Name string
+	tags []string
+}
+
+type diffSpec struct {
+	Items []diffItem
+	Attrs map[string]any
+	Next  *diffSpec
+}
+
+// TestDiff executes all of the tests described by diffTests.
+func TestDiff(t *testing.T) {
+	cfgDefault := spew.NewDefaultConfig()
+	cfgSorted := &spew.Config{SortKeys: true}
+	cfgNoUnexported := &spew.Config{DisableUnexported: true}
+	cfgNoMethods := &spew.Config{DisableMethods: true}
+
+	spec := func() diffSpec {
+		return diffSpec{
+			Items: []diffItem{{Name: "a"}, {Name: "b", tags: []string{"x"}}},
+			Attrs: map[string]any{"one": 1, "two": "2"},
+		}
+	}
+	renamed := spec()
+	renamed.Items = []diffItem{{Name: "a"}, {Name: "c", tags: []string{"x"}}}
+	retagged := spec()
+	retagged.Items = []diffItem{{Name: "a"}, {Name: "b", tags: []string{"y"}}}
+	grown := spec()
+	grown.Items = append(grown.Items, diffItem{Name: "d"})
+	changedAttrs := spec()
+	changedAttrs.Attrs = map[string]any{"one": "1", "three": 3}
+
+	// Circular structures which are equal, and which differ.
+	circA := &diffSpec{Attrs: map[string]any{}}
+	circA.Next = circA
+	circB := &diffSpec{Attrs: map[string]any{}}
+	circB.Next = circB
+	circC := &diffSpec{Items: []diffItem{}, Attrs: map[string]any{}}
+	circC.Next = circC
+
+	tests := []diffTest{
+		{line(), cfgDefault, 1, 1, ""},
+		{line(), cfgDefault, 1, 2, "1 -> 2\n"},
+		{line(), cfgDefault, "a", "b", `"a" -> "b"` + "\n"},
+		{line(), cfgDefault, 1, "1", `(int)1 -> (string)"1"` + "\n"},
+		{line(), cfgDefault, nil, 1, "<missing> -> (int)1\n"},
+		{line(), cfgDefault, spec(), spec(), ""},
+		{line(), cfgDefault, spec(), renamed, `.Items[1].Name: "b" -> "c"` + "\n"},
+		{line(), cfgDefault, spec(), retagged, `.Items[1].tags[0]: "x" -> "y"` + "\n"},
+		{line(), cfgNoUnexported, spec(), retagged, ""},
+		{line(), cfgDefault, spec(), grown, `.Items[2]: <missing> -> {"d" <nil>}` + "\n"},
+		{line(), cfgDefault, grown, spec(), `.Items[2]: {"d" <nil>} -> <missing>` + "\n"},
+		{line(), cfgSorted, spec(), changedAttrs, `.Attrs["one"]: (int)1 -> (string)"1"` + "\n" +
+			`.Attrs["three"]: <missing> -> 3` + "\n" +
+			`.Attrs["two"]: "2" -> <missing>` + "\n"},
+		{line(), cfgDefault, []int(nil), []int{}, "<nil> -> []\n"},
+		{line(), cfgDefault, &spec, &spec, ""},
+		{line(), cfgDefault, circA, circB, ""},
+		{line(), cfgDefault, circA, circC, ".Items: <nil> -> []\n"},
+		{line(), cfgDefault, stringer("a"), stringer("b"), `"stringer a" -> "stringer b"` + "\n"},
+		{line(), cfgNoMethods, stringer("a"), stringer("b"), `"a" -> "b"` + "\n"},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		s := test.cfg.Diff(test.a, test.b)
+		if s != test.want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, s, test.want)
+		}
+	}
+
+	if s := spew.Diff(spec(), renamed); s != `.Items[1].Name: "b" -> "c"`+"\n" {
+		t.Errorf("spew.Diff:\n got: %s", s)
+	}
+}
@@ -27,6 +27,8 @@
 //   - Custom types which only implement the Stringer/error interfaces via
 //     a pointer receiver are optionally invoked when passing non-pointer
 //     variables
+//   - Custom types can take over their own output, nested at the current
+//     indentation, by implementing the SpewDumper interface
 //   - Byte arrays and slices are dumped like the hexdump -C command which
 //     includes offsets, byte values in hex, and ASCII output (only when using
 //     Dump style)
@@ -84,6 +86,28 @@
 //     Maximum number of levels to descend into nested data structures.
 //     There is no limit by default.
 //
+//   - DepthOverrides
+//     Limits which replace MaxDepth for the values of a type or at the
+//     paths matching a glob pattern, counted from those values.  There are
+//     no overrides by default.
+//
+//   - MaxElements
+//     Maximum number of elements of arrays, slices and maps, other than byte
+//     arrays and slices, to output.  There is no limit by default.
+//
+//   - MaxStringLen
+//     Maximum number of bytes of strings, byte arrays and byte slices to
+//     output.  There is no limit by default.
+//
+//   - ElideMiddle
+//     Output both the first and the last elements or bytes when MaxElements
+//     or MaxStringLen is exceeded, rather than only the first.
+//
+//   - MaxBytes
+//     Approximate maximum number of bytes of output, after which the output
+//     is cut short and ends with <truncated after N bytes>.  The Formatter
+//     applies it to each operand separately.  There is no limit by default.
+//
 //   - DisableMethods
 //     Disables invocation of error and Stringer interface methods.
 //     Method invocation is enabled by default.
@@ -97,6 +121,17 @@
 //     DisablePointerAddresses specifies whether to disable the printing of
 //     pointer addresses. This is useful when diffing data structures in tests.
 //
+//   - PointerOrdinals
+//     PointerOrdinals specifies whether to replace pointer addresses with
+//     ordinals such as &1, assigned in the order the addresses are first
+//     seen.  This keeps output stable across runs while still showing
+//     which pointers are shared.
+//
+//   - DedupeShared
+//     DedupeShared specifies whether to show values shared by
+//     multiple pointers only once, with later references shown as
+//     <see PATH>.  Shared values are shown every time by default.
+//
 //   - DisableCapacities
 //     DisableCapacities specifies whether to disable the printing of
 //     capacities for arrays, slices, maps and channels. This is useful when
@@ -116,7 +151,8 @@
 //   - TrailingCommas
 //     TrailingCommas specifies whether to always include a
 //     trailing comma, Go-style. This is useful to avoid false positives when
-//     diffing data structures in tests.
+//     diffing data structures in tests.  The Formatter only includes it when
+//     Commas is also set.
 //
 //   - DisableUnexported
 //     DisableUnexported specifies whether to disable the unexported fields of
@@ -156,6 +192,74 @@
 //     spewed to strings and sorted by those strings.  This is only
 //     considered if SortKeys is true.
 //
+//   - OmitNilFields
+//     Skips struct fields and map entries whose values are nil.  Nil
+//     elements of arrays and slices are still output.  Nothing is skipped by
+//     default.
+//
+//   - OmitZeroFields
+//     Skips struct fields and map entries whose values are the zero value
+//     for their type.  Zero elements of arrays and slices are still output.
+//     Nothing is skipped by default.
+//
+//   - Color
+//     Colorizes Dump and Formatter output with ANSI escape sequences.
+//     ColorScheme selects the sequences, and IsTerminal is an optional hint
+//     which disables colors for writers that are not terminals.  Colors are
+//     also disabled when the NO_COLOR environment variable is set.  Colors
+//     are disabled by default.
+//
+//   - Redact
+//     Patterns for the names and paths of struct fields and string map keys
+//     whose values are output as <redacted len=N> rather than their
+//     content, in every output format.  Nothing is redacted by default.
+//
+//   - RedactValues
+//     Patterns for string values which are output as <redacted len=N>
+//     rather than their content.  Nothing is redacted by default.
+//
+//   - Paths
+//     Paths to the parts of each value which Dump outputs, such as
+//     .Spec.Containers[*].Env, each preceded by its full path.  See Select
+//     for the path language.  Values are dumped whole by default.
+//
+//   - IncludeFields, ExcludeFields
+//     Glob patterns for the paths of struct fields and map entries to
+//     output or leave out, such as *.ObjectMeta.ManagedFields or **.XXX_*,
+//     where ** matches any number of names.  Everything is output by
+//     default.
+//
+// # Struct Tags
+//
+// The output of struct fields can be controlled with a spew struct tag, which
+// is a comma-separated list of the following options:
+//
+//   - -
+//     Skips the field.
+//
+//   - redact
+//     Outputs <redacted> in place of the value of the field.
+//
+//   - hex
+//     Outputs the integers within the field in hexadecimal.
+//
+//   - omitempty
+//     Skips the field when its value is the zero value for its type.
+//
+// For example:
+//
+//	type Account struct {
+//		Name     string
+//		Password string `spew:"redact"`
+//		Flags    uint32 `spew:"hex,omitempty"`
+//	}
+//
+// The tags are honored by every output format, with two exceptions.  The hex
+// option is not applied by the JSON output, which has no notation for
+// hexadecimal numbers, or by Diff and Fdot, which render each value on its
+// own rather than as part of its struct.  The Go syntax output can't express
+// a redacted value, so it leaves a comment in place of the field instead.
+//
 // # Dump Usage
 //
 // Simply call spew.Dump with a list of variables you want to dump:
@@ -167,6 +271,16 @@
 //
 //	spew.Fdump(os.Stderr, myVar1, myVar2, ...)
 //
+// Use spew.FdumpErr instead when write errors matter, such as when dumping to
+// a network connection.  It stops at the first write error and returns it:
+//
+//	n, err := spew.FdumpErr(conn, myVar1, myVar2, ...)
+//
+// Likewise, spew.FdumpContext stops once a context.Context is done, such as
+// when dumping a huge value from a request handler:
+//
+//	err := spew.FdumpContext(req.Context(), w, myVar1, myVar2, ...)
+//
 // A third option is to call spew.Sdump to get the formatted output as a string:
 //
 //	str := spew.Sdump(myVar1, myVar2, ...)
@@ -0,0 +1,310 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// dotEscaper escapes the characters which are special in the labels of
+// Graphviz record nodes.
+var dotEscaper = strings.NewReplacer(
+	`\`, `\\`, `"`, `\"`, "{", `\{`, "}", `\}`, "|", `\|`, "<", `\<`, ">", `\>`,
+	"\n", `\n`,
+)
+
+// dotState contains information about the state of a DOT output operation.
+type dotState struct {
+	w      io.Writer
+	depth  int
+	cfg    *Config
+	fmtCfg Config
+
+	// nodes maps the address of every pointer which has been followed to the
+	// id of the node for the value it points to, so that shared and circular
+	// references become edges to the same node.
+	nodes  map[uintptr]int
+	lastID int
+
+	// path is the path of the value being output in the form Dump uses,
+	// such as .Items[3].Name, which the Redact option is matched against.
+	path string
+}
+
+// unpackValue returns values inside of non-nil interfaces when possible.
+func (d *dotState) unpackValue(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// newID returns the id for a new node.
+func (d *dotState) newID() int {
+	d.lastID++
+	return d.lastID
+}
+
+// inline returns the text to output for v if it is output inline rather than
+// as a node of its own.  Empty collections and byte arrays and slices are
+// output inline, as is the output of error and Stringer interfaces.
+func (d *dotState) inline(v reflect.Value) (string, bool) {
+	if !d.cfg.DisableMethods && v.Kind() != reflect.Interface {
+		if s, handled := d.methodString(v); handled {
+			return s, true
+		}
+	}
+	if isCollection(v) {
+		return "", false
+	}
+	return d.sprint(v), true
+}
+
+// methodString returns the result of invoking the error or Stringer interface
+// on v, if there is one and it is not configured to continue on to the
+// underlying value.
+func (d *dotState) methodString(v reflect.Value) (string, bool) {
+	var buf bytes.Buffer
+	if handled := handleMethods(&d.fmtCfg, &buf, v, Context{Config: d.cfg, Depth: d.depth, Inline: true}); handled {
+		return buf.String(), true
+	}
+	return "", false
+}
+
+// target returns the id of the node v is output as, or the text to output
+// inline for it if it isn't a node of its own.  Pointers are followed the same
+// way dump follows them, and dashed is set if v is not reached through one.
+func (d *dotState) target(v reflect.Value) (id int, text string, dashed bool) {
+	v = d.unpackValue(v)
+	if v.Kind() != reflect.Ptr {
+		if text, ok := d.inline(v); ok {
+			return 0, text, false
+		}
+		id = d.newID()
+		d.node(id, v, nil)
+		return id, "", true
+	}
+
+	// Every pointer in the chain leads to the same node, so a pointer which
+	// was already followed ends the chain with an edge to its node.
+	id = d.newID()
+	var added []uintptr
+	ve, chain, nilFound, seenFound := followPointers(v, func(addr uintptr) bool {
+		if _, ok := d.nodes[addr]; ok {
+			return true
+		}
+		d.nodes[addr] = id
+		added = append(added, addr)
+		return false
+	})
+
+	// No node is written for a chain which ends in nil or loops back on
+	// itself, so later pointers to the same addresses must not lead to it.
+	looped := seenFound && d.nodes[chain[len(chain)-1]] == id
+	if nilFound || looped {
+		for _, addr := range added {
+			delete(d.nodes, addr)
+		}
+		d.lastID = id - 1
+	}
+	switch {
+	case looped:
+		return 0, string(circularBytes), false
+
+	case seenFound:
+		return d.nodes[chain[len(chain)-1]], "", false
+
+	case nilFound:
+		return 0, string(nilAngleBytes), false
+	}
+	d.node(id, ve, chain)
+	return id, "", false
+}
+
+// sprint returns v rendered inline in the same way as the Formatter.
+func (d *dotState) sprint(v reflect.Value) string {
+	if !v.IsValid() {
+		return string(invalidAngleBytes)
+	}
+	return sprintValue(&d.fmtCfg, v, "")
+}
+
+// node outputs v as a node with the passed id, followed by the edges to the
+// nodes for the values it holds.  Every entry of a struct, map, array or slice
+// is output as a row of the node.  Values reached through the pointers in
+// chain may be output as a single row instead.
+func (d *dotState) node(id int, v reflect.Value, chain []uintptr) {
+	// Display type and pointer information in the first row.
+	var header bytes.Buffer
+	if !d.cfg.DisableTypes {
+		header.WriteString(v.Type().String())
+	}
+	if !d.cfg.DisablePointerAddresses && len(chain) > 0 {
+		if header.Len() > 0 {
+			header.Write(spaceBytes)
+		}
+		printHexPtr(&header, chain[len(chain)-1])
+	}
+	if !d.cfg.DisableLengths {
+		switch v.Kind() {
+		case reflect.Array, reflect.Slice, reflect.Map:
+			if header.Len() > 0 {
+				header.Write(spaceBytes)
+			}
+			header.Write(openParenBytes)
+			header.Write(lenEqualsBytes)
+			printInt(&header, int64(v.Len()), 10)
+			header.Write(closeParenBytes)
+		}
+	}
+
+	var rows []string
+	var edges []string
+	if header.Len() > 0 {
+		rows = append(rows, dotEscaper.Replace(header.String()))
+	}
+
+	// Add a row, and an edge if needed, for each entry.
+	addRow := func(name string, ev reflect.Value) {
+		port := "f" + strconv.Itoa(len(rows))
+		target, text, dashed := d.target(ev)
+		if name != "" {
+			name += ": "
+		}
+		rows = append(rows, "<"+port+"> "+dotEscaper.Replace(name+text))
+		if target != 0 {
+			edge := fmt.Sprintf("n%d:%s -> n%d", id, port, target)
+			if dashed {
+				edge += " [style=dashed]"
+			}
+			edges = append(edges, edge)
+		}
+	}
+
+	// Add a row without any edge for each entry which is redacted.
+	addRedactedRow := func(name string, marker []byte) {
+		port := "f" + strconv.Itoa(len(rows))
+		rows = append(rows, "<"+port+"> "+dotEscaper.Replace(name+": "+string(marker)))
+	}
+
+	d.depth++
+	path := d.path
+	text, inline := "", false
+	if len(chain) > 0 {
+		text, inline = d.inline(v)
+	}
+	switch {
+	case inline:
+		rows = append(rows, dotEscaper.Replace(text))
+
+	case d.cfg.MaxDepth != 0 && d.depth > d.cfg.MaxDepth:
+		rows = append(rows, dotEscaper.Replace(string(maxNewlineBytes[:len(maxNewlineBytes)-1])))
+
+	case v.Kind() == reflect.Map:
+		keys := v.MapKeys()
+		if d.cfg.SortKeys {
+			sortValues(keys, d.cfg)
+		}
+		for _, key := range keys {
+			uk := d.unpackValue(key)
+			d.path = keyPath(d.cfg, path, uk)
+			if uk.Kind() == reflect.String && redactName(d.cfg, uk.String(), d.path) {
+				addRedactedRow(d.sprint(uk), redactedLen(d.unpackValue(v.MapIndex(key))))
+				continue
+			}
+			addRow(d.sprint(uk), v.MapIndex(key))
+		}
+
+	case v.Kind() == reflect.Struct:
+		vt := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			vtf := vt.Field(i)
+			// StructField has an IsExported() method, but only in 1.17+.
+			if d.cfg.DisableUnexported && vtf.PkgPath != "" {
+				continue
+			}
+			field, ok := fieldTag(v, i)
+			if !ok {
+				continue
+			}
+			d.path = path + "." + vtf.Name
+			switch {
+			case field.redact:
+				addRedactedRow(vtf.Name, redactedBytes)
+			case redactName(d.cfg, vtf.Name, d.path):
+				addRedactedRow(vtf.Name, redactedLen(d.unpackValue(v.Field(i))))
+			default:
+				addRow(vtf.Name, v.Field(i))
+			}
+		}
+
+	default:
+		for i := 0; i < v.Len(); i++ {
+			d.path = indexPath(path, i)
+			addRow("", v.Index(i))
+		}
+	}
+	d.path = path
+	d.depth--
+
+	fmt.Fprintf(d.w, "%sn%d [label=\"{%s}\"];\n", d.cfg.Indent, id, strings.Join(rows, "|"))
+	for _, edge := range edges {
+		fmt.Fprintf(d.w, "%s%s;\n", d.cfg.Indent, edge)
+	}
+}
+
+// fdot is a helper function to consolidate the logic from the various public
+// methods which take varying writers and config states.
+func fdot(cfg *Config, w io.Writer, a any) {
+	d := dotState{w: w, cfg: cfg, fmtCfg: *cfg}
+	d.fmtCfg.QuoteStrings = true
+	d.nodes = make(map[uintptr]int)
+
+	fmt.Fprintf(w, "digraph spew {\n%snode [shape=record];\n", cfg.Indent)
+	// Values which are output inline still get a node of their own at the top
+	// level.
+	v := d.unpackValue(reflect.ValueOf(a))
+	if id, text, _ := d.target(v); id == 0 {
+		label := dotEscaper.Replace(text)
+		if v.IsValid() && !cfg.DisableTypes {
+			label = "{" + dotEscaper.Replace(v.Type().String()) + "|" + label + "}"
+		}
+		fmt.Fprintf(w, "%sn%d [label=\"%s\"];\n", cfg.Indent, d.newID(), label)
+	}
+	w.Write(closeBraceBytes)
+	w.Write(newlineBytes)
+}
+
+// Fdot formats and displays the passed argument to io.Writer w as a Graphviz
+// DOT graph, which can be rendered with the dot command.  Every struct, map,
+// array and slice is output as a node with a row for each of its entries, and
+// every pointer is output as an edge to the node for the value it points to.
+// Pointers are followed the same way Dump follows them, but every pointer to
+// the same address leads to the same node, so shared and circular references
+// show up as edges rather than as <already shown>.  Values which are held
+// directly rather than through pointers are output as dashed edges.
+//
+// The configuration options are controlled by an exported package global,
+// spew.Default.  See Config for options documentation.
+func Fdot(w io.Writer, a any) {
+	fdot(&Default, w, a)
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// dotTest is used to describe a test to be performed against the Fdot method.
+type dotTest struct {
+	line string // use line() to fill this
+	cfg  *spew.Config
+	in   any
+	want string
+}
+
+// dotNode is used to test linked structures with shared and circular
+// references.
+type dotNode struct {
+	Name string
+	Next *dotNode
+	Prev *dotNode
+}
+
+// TestDot executes all of the tests described by dotTests.
+func TestDot(t *testing.T) {
+	cfg := &spew.Config{Indent: "\t", DisablePointerAddresses: true, SortKeys: true}
+	cfgClean := &spew.Config{Indent: "\t", DisablePointerAddresses: true,
+		DisableTypes: true, DisableLengths: true}
+	cfgMaxDepth := &spew.Config{Indent: "\t", DisableTypes: true, MaxDepth: 1}
+
+	a := &dotNode{Name: "a"}
+	b := &dotNode{Name: "b", Prev: a}
+	a.Next = b
+	shared := &dotNode{Name: "shared"}
+	var none *dotNode
+	pnone := &none
+
+	tests := []dotTest{
+		{line(), cfg, 5, "digraph spew {\n" +
+			"\tnode [shape=record];\n" +
+			"\tn1 [label=\"{int|5}\"];\n" +
+			"}\n"},
+		{line(), cfgClean, "a|b", "digraph spew {\n" +
+			"\tnode [shape=record];\n" +
+			"\tn1 [label=\"\\\"a\\|b\\\"\"];\n" +
+			"}\n"},
+		{line(), cfg, a, "digraph spew {\n" +
+			"\tnode [shape=record];\n" +
+			"\tn2 [label=\"{spew_test.dotNode|<f1> Name: \\\"b\\\"|<f2> Next: \\<nil\\>|<f3> Prev: }\"];\n" +
+			"\tn2:f3 -> n1;\n" +
+			"\tn1 [label=\"{spew_test.dotNode|<f1> Name: \\\"a\\\"|<f2> Next: |<f3> Prev: \\<nil\\>}\"];\n" +
+			"\tn1:f2 -> n2;\n" +
+			"}\n"},
+		{line(), cfgClean, []*dotNode{shared, shared}, "digraph spew {\n" +
+			"\tnode [shape=record];\n" +
+			"\tn2 [label=\"{<f0> Name: \\\"shared\\\"|<f1> Next: \\<nil\\>|<f2> Prev: \\<nil\\>}\"];\n" +
+			"\tn1 [label=\"{<f0> |<f1> }\"];\n" +
+			"\tn1:f0 -> n2;\n" +
+			"\tn1:f1 -> n2;\n" +
+			"}\n"},
+		{line(), cfgClean, []**dotNode{pnone, pnone}, "digraph spew {\n" +
+			"\tnode [shape=record];\n" +
+			"\tn1 [label=\"{<f0> \\<nil\\>|<f1> \\<nil\\>}\"];\n" +
+			"}\n"},
+		{line(), cfg, map[string][]int{"x": {1}, "y": {}}, "digraph spew {\n" +
+			"\tnode [shape=record];\n" +
+			"\tn2 [label=\"{[]int (len=1)|<f1> 1}\"];\n" +
+			"\tn1 [label=\"{map[string][]int (len=2)|<f1> \\\"x\\\": |<f2> \\\"y\\\": []}\"];\n" +
+			"\tn1:f1 -> n2 [style=dashed];\n" +
+			"}\n"},
+		{line(), cfgClean, []stringer{"x"}, "digraph spew {\n" +
+			"\tnode [shape=record];\n" +
+			"\tn1 [label=\"{<f0> \\\"stringer x\\\"}\"];\n" +
+			"}\n"},
+		{line(), cfgMaxDepth, [][]int{{1}}, "digraph spew {\n" +
+			"\tnode [shape=record];\n" +
+			"\tn2 [label=\"{(len=1)|\\<max depth reached\\>}\"];\n" +
+			"\tn1 [label=\"{(len=1)|<f1> }\"];\n" +
+			"\tn1:f1 -> n2 [style=dashed];\n" +
+			"}\n"},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		var buf bytes.Buffer
+		test.cfg.Fdot(&buf, test.in)
+		if s := buf.String(); s != test.want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, s, test.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	spew.Fdot(&buf, 1)
+	if s := buf.String(); s != "digraph spew {\n node [shape=record];\n n1 [label=\"{int|1}\"];\n}\n" {
+		t.Errorf("spew.Fdot:\n got: %s", s)
+	}
+}
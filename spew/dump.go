@@ -18,15 +18,13 @@ package spew
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"reflect"
 	"regexp"
-	"runtime"
-	"strconv"
 	"strings"
 )
 
@@ -49,118 +47,201 @@ var (
 	cUint8tCharRE = regexp.MustCompile(`^.*\._Ctype_uint8_t$`)
 )
 
-// dumpState contains information about the state of a dump operation.
+// dumpState contains information about the state of a dump operation.  It
+// renders the values reported by its walker in the multi-line Dump style.
 type dumpState struct {
-	w                io.Writer
-	depth            int
-	pointers         map[uintptr]int
-	ignoreNextType   bool
-	ignoreNextIndent bool
-	cfg              *Config
+	*walker
+	w      io.Writer
+	colors ColorScheme
+
+	// ordinals is only set when the PointerOrdinals option is set, and is
+	// shared by all of the arguments to a single call.
+	ordinals pointerOrdinals
+}
+
+// newDumpState returns a dumpState which dumps to w with cfg.
+func newDumpState(cfg *Config, w io.Writer) *dumpState {
+	d := &dumpState{w: w}
+	d.walker = newWalker(cfg, d)
+	return d
+}
+
+// dump dumps the value v.
+func (d *dumpState) dump(v reflect.Value) {
+	d.walk(v, slotTop)
 }
 
 // indent performs indentation according to the depth level and cfg.Indent
 // option.
 func (d *dumpState) indent() {
-	if d.ignoreNextIndent {
-		d.ignoreNextIndent = false
-		return
-	}
 	d.w.Write(bytes.Repeat([]byte(d.cfg.Indent), d.depth))
 }
 
-// unpackValue returns values inside of non-nil interfaces when possible.
-// This is useful for data types like structs, arrays, slices, and maps which
-// can contain varying types packed inside an interface.
-func (d *dumpState) unpackValue(v reflect.Value) reflect.Value {
-	if v.Kind() == reflect.Interface && !v.IsNil() {
-		v = v.Elem()
-	}
-	return v
+func (d *dumpState) writer() io.Writer {
+	return d.w
 }
 
-// dumpPtr handles formatting of pointers by indirecting them as necessary.
-func (d *dumpState) dumpPtr(v reflect.Value) {
-	// Remove pointers at or below the current depth from map used to detect
-	// circular refs.
-	for k, depth := range d.pointers {
-		if depth >= d.depth {
-			delete(d.pointers, k)
-		}
+func (d *dumpState) context() Context {
+	return d.methodContext(false)
+}
+
+// header indents values on lines of their own and displays their types.  The
+// types of the values pointers point to are displayed by enterPointer.
+func (d *dumpState) header(v reflect.Value, s slot, iface bool) {
+	if s == slotPointee {
+		return
+	}
+	if s != slotField && s != slotValue {
+		d.indent()
 	}
+	if !d.cfg.DisableTypes {
+		writeColor(d.w, d.colors.Type, []byte("("+v.Type().String()+")"))
+		d.w.Write(spaceBytes)
+	}
+}
 
-	// Keep list of all dereferenced pointers to show later.
-	pointerChain := make([]uintptr, 0)
-
-	// Figure out how many levels of indirection there are by dereferencing
-	// pointers and unpacking interfaces down the chain while detecting circular
-	// references.
-	nilFound := false
-	cycleFound := false
-	indirects := 0
-	ve := v
-	for ve.Kind() == reflect.Ptr {
-		if ve.IsNil() {
-			nilFound = true
-			break
+// lengths displays length and capacity if the built-in len and cap functions
+// work with the value's kind and the len/cap itself is non-zero.
+func (d *dumpState) lengths(v reflect.Value) {
+	if d.cfg.DisableLengths {
+		return
+	}
+	valueLen, valueCap := 0, 0
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Chan:
+		valueLen, valueCap = v.Len(), v.Cap()
+	case reflect.Map, reflect.String:
+		valueLen = v.Len()
+	}
+	if valueLen != 0 || !d.cfg.DisableCapacities && valueCap != 0 {
+		d.w.Write(openParenBytes)
+		if valueLen != 0 {
+			d.w.Write(lenEqualsBytes)
+			printInt(d.w, int64(valueLen), 10)
 		}
-		indirects++
-		addr := ve.Pointer()
-		pointerChain = append(pointerChain, addr)
-		if pd, ok := d.pointers[addr]; ok && pd < d.depth {
-			cycleFound = true
-			indirects--
-			break
+		if !d.cfg.DisableCapacities && valueCap != 0 {
+			if valueLen != 0 {
+				d.w.Write(spaceBytes)
+			}
+			d.w.Write(capEqualsBytes)
+			printInt(d.w, int64(valueCap), 10)
 		}
-		d.pointers[addr] = d.depth
+		d.w.Write(closeParenBytes)
+		d.w.Write(spaceBytes)
+	}
+}
 
-		ve = ve.Elem()
-		if ve.Kind() == reflect.Interface {
-			if ve.IsNil() {
-				nilFound = true
-				break
-			}
-			ve = ve.Elem()
+func (d *dumpState) leaf(v reflect.Value) {
+	if printLeaf(d.w, d.cfg, d.colors, v, d.hex) {
+		return
+	}
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		printString(d.w, d.cfg, d.colors, s, true, d.counter.limit(d.cfg.MaxStringLen, len(s)))
+
+	// There were not any other types at the time this code was written, but
+	// fall back to letting the default fmt package handle it in case any new
+	// types are added.
+	default:
+		if v.CanInterface() {
+			fmt.Fprintf(d.w, "%v", v.Interface())
+		} else {
+			fmt.Fprintf(d.w, "%v", v.String())
 		}
 	}
+}
+
+func (d *dumpState) invalid() {
+	writeColor(d.w, d.colors.Nil, invalidAngleBytes)
+}
+
+func (d *dumpState) nilValue() {
+	writeColor(d.w, d.colors.Nil, nilAngleBytes)
+}
+
+func (d *dumpState) circular() {
+	writeColor(d.w, d.colors.Marker, circularBytes)
+}
+
+// maxDepth emits the marker for values nested deeper than MaxDepth on a line
+// of its own.
+func (d *dumpState) maxDepth() {
+	d.indent()
+	writeColor(d.w, d.colors.Marker, maxNewlineBytes[:len(maxNewlineBytes)-1])
+	d.w.Write(newlineBytes)
+}
+
+func (d *dumpState) marker(b []byte) {
+	writeColor(d.w, d.colors.Marker, b)
+}
+
+// enterPointer displays the type and the addresses of the chain of pointers,
+// which are always shown, followed by the opening parenthesis around the
+// dereferenced value.
+func (d *dumpState) enterPointer(p *pointerInfo, s slot, iface bool) {
+	if s != slotField && s != slotValue {
+		d.indent()
+	}
 
 	// Display type information.
+	setColor(d.w, d.colors.Type)
 	d.w.Write(openParenBytes)
-	d.w.Write(bytes.Repeat(asteriskBytes, indirects))
-	d.w.Write([]byte(ve.Type().String()))
+	d.w.Write(bytes.Repeat(asteriskBytes, p.indirects))
+	d.w.Write([]byte(p.ve.Type().String()))
 	d.w.Write(closeParenBytes)
+	resetColor(d.w, d.colors.Type)
 
 	// Display pointer information.
-	if !d.cfg.DisablePointerAddresses && len(pointerChain) > 0 {
+	if !d.cfg.DisablePointerAddresses && len(p.chain) > 0 {
 		d.w.Write(openParenBytes)
-		for i, addr := range pointerChain {
+		for i, addr := range p.chain {
 			if i > 0 {
 				d.w.Write(pointerChainBytes)
 			}
-			printHexPtr(d.w, addr)
+			setColor(d.w, d.colors.Address)
+			printPtr(d.w, addr, d.ordinals)
+			resetColor(d.w, d.colors.Address)
 		}
 		d.w.Write(closeParenBytes)
 	}
-
-	// Display dereferenced value.
 	d.w.Write(openParenBytes)
-	switch {
-	case nilFound:
-		d.w.Write(nilAngleBytes)
+}
 
-	case cycleFound:
-		d.w.Write(circularBytes)
+func (d *dumpState) leavePointer() {
+	d.w.Write(closeParenBytes)
+}
 
-	default:
-		d.ignoreNextType = true
-		d.dump(ve)
+func (d *dumpState) empty(v reflect.Value) {
+	if v.Kind() == reflect.Slice {
+		d.w.Write(emptyListBytes)
+	} else {
+		d.w.Write(emptyBracesBytes)
+	}
+}
+
+func (d *dumpState) open(v reflect.Value) {
+	kind := v.Kind()
+	if d.cfg.DumpListSquareBraces && (kind == reflect.Array || kind == reflect.Slice) {
+		d.w.Write(openListNewlineBytes)
+	} else {
+		d.w.Write(openBraceNewlineBytes)
+	}
+}
+
+func (d *dumpState) close(v reflect.Value) {
+	d.indent()
+	kind := v.Kind()
+	if d.cfg.DumpListSquareBraces && (kind == reflect.Array || kind == reflect.Slice) {
+		d.w.Write(closeListBytes)
+	} else {
+		d.w.Write(closeBraceBytes)
 	}
-	d.w.Write(closeParenBytes)
 }
 
-// dumpSlice handles formatting of arrays and slices.  Byte (uint8 under
-// reflection) arrays and slices are dumped in hexdump -C fashion.
-func (d *dumpState) dumpSlice(v reflect.Value) {
+// bytes dumps byte (uint8 under reflection) arrays and slices in hexdump -C
+// fashion.
+func (d *dumpState) bytes(v reflect.Value) bool {
 	// Determine whether this type should be hex dumped or not.  Also,
 	// for types which should be hexdumped, try to use the underlying data
 	// first, then fall back to trying to convert them to a uint8 slice.
@@ -224,294 +305,134 @@ func (d *dumpState) dumpSlice(v reflect.Value) {
 			doHexDump = true
 		}
 	}
-
-	// Hexdump the entire slice as needed.
-	if doHexDump {
-		indent := strings.Repeat(d.cfg.Indent, d.depth)
-		str := indent + hex.Dump(buf)
-		str = strings.ReplaceAll(str, "\n", "\n"+indent)
-		str = strings.TrimRight(str, d.cfg.Indent)
-		d.w.Write([]byte(str))
-		return
+	if !doHexDump {
+		return false
 	}
 
-	// Recursively call dump for each item.
-	for i := 0; i < numEntries; i++ {
-		d.dump(d.unpackValue(v.Index(i)))
-		d.writeComma(i < (numEntries - 1))
+	// Hexdump the slice, eliding the bytes in excess of MaxStringLen.
+	var b bytes.Buffer
+	limit := d.counter.limit(d.cfg.MaxStringLen, len(buf))
+	head, tail := truncate(d.cfg, len(buf), limit)
+	b.WriteString(hex.Dump(buf[:head]))
+	if more := len(buf) - head - tail; more > 0 {
+		writeColor(&b, d.colors.Marker, moreBytes(more))
+		b.Write(newlineBytes)
+		if tail > 0 {
+			b.WriteString(hexDumpAt(buf[len(buf)-tail:], len(buf)-tail))
+		}
 	}
+	indent := strings.Repeat(d.cfg.Indent, d.depth)
+	str := indent + b.String()
+	str = strings.ReplaceAll(str, "\n", "\n"+indent)
+	str = strings.TrimRight(str, d.cfg.Indent)
+	d.w.Write([]byte(str))
+	return true
 }
 
-// dump is the main workhorse for dumping a value.  It uses the passed reflect
-// value to figure out what kind of object we are dealing with and formats it
-// appropriately.  It is a recursive function, however circular data structures
-// are detected and handled properly.
-func (d *dumpState) dump(v reflect.Value) {
-	// Handle invalid reflect values immediately.
-	kind := v.Kind()
-	if kind == reflect.Invalid {
-		d.w.Write(invalidAngleBytes)
-		return
-	}
-
-	// Handle pointers specially.
-	if kind == reflect.Ptr {
-		d.indent()
-		d.dumpPtr(v)
-		return
-	}
-
-	// Print type information unless already handled elsewhere.
-	if !d.ignoreNextType {
-		d.indent()
-		if !d.cfg.DisableTypes {
-			d.w.Write(openParenBytes)
-			d.w.Write([]byte(v.Type().String()))
-			d.w.Write(closeParenBytes)
-			d.w.Write(spaceBytes)
-		}
-	}
-	d.ignoreNextType = false
-
-	// Display length and capacity if the built-in len and cap functions
-	// work with the value's kind and the len/cap itself is non-zero.
-	if !d.cfg.DisableLengths {
-		valueLen, valueCap := 0, 0
-		switch v.Kind() {
-		case reflect.Array, reflect.Slice, reflect.Chan:
-			valueLen, valueCap = v.Len(), v.Cap()
-		case reflect.Map, reflect.String:
-			valueLen = v.Len()
-		}
-		if valueLen != 0 || !d.cfg.DisableCapacities && valueCap != 0 {
-			d.w.Write(openParenBytes)
-			if valueLen != 0 {
-				d.w.Write(lenEqualsBytes)
-				printInt(d.w, int64(valueLen), 10)
-			}
-			if !d.cfg.DisableCapacities && valueCap != 0 {
-				if valueLen != 0 {
-					d.w.Write(spaceBytes)
-				}
-				d.w.Write(capEqualsBytes)
-				printInt(d.w, int64(valueCap), 10)
-			}
-			d.w.Write(closeParenBytes)
-			d.w.Write(spaceBytes)
-		}
+// beginItem ends the line of the item before it with a comma.
+func (d *dumpState) beginItem(first bool) {
+	if !first {
+		d.w.Write(commaNewlineBytes)
 	}
+}
 
-	// Call Stringer/error interfaces if they exist and the handle methods flag
-	// is enabled
-	if !d.cfg.DisableMethods {
-		if (kind != reflect.Invalid) && (kind != reflect.Interface) {
-			if handled := handleMethods(d.cfg, d.w, v); handled {
-				return
-			}
-		}
+// endItems ends the line of the last item, with a comma if trailing commas
+// are always enabled.
+func (d *dumpState) endItems() {
+	if d.cfg.TrailingCommas {
+		d.w.Write(commaNewlineBytes)
+	} else {
+		d.w.Write(newlineBytes)
 	}
+}
 
-	switch kind {
-	case reflect.Invalid:
-		// Do nothing.  We should never get here since invalid has already
-		// been handled above.
-
-	case reflect.Bool:
-		printBool(d.w, v.Bool())
-
-	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
-		printInt(d.w, v.Int(), 10)
-
-	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
-		printUint(d.w, v.Uint(), 10)
+// more emits the marker for more elided entries on a line of its own.
+func (d *dumpState) more(n int) {
+	d.indent()
+	writeColor(d.w, d.colors.Marker, moreBytes(n))
+}
 
-	case reflect.Float32:
-		printFloat(d.w, v.Float(), 32)
+func (d *dumpState) element(i int, v reflect.Value) {}
 
-	case reflect.Float64:
-		printFloat(d.w, v.Float(), 64)
+func (d *dumpState) field(name string, v reflect.Value) {
+	d.indent()
+	writeColor(d.w, d.colors.Field, []byte(name))
+	d.w.Write(colonSpaceBytes)
+}
 
-	case reflect.Complex64:
-		printComplex(d.w, v.Complex(), 32)
+func (d *dumpState) key(k, v reflect.Value) {
+	d.walk(k, slotKey)
+	d.w.Write(colonSpaceBytes)
+}
 
-	case reflect.Complex128:
-		printComplex(d.w, v.Complex(), 64)
+// fdump is a helper function to consolidate the logic from the various public
+// methods which take varying writers and config states.  It returns the number
+// of bytes written and the first write error, which stops the output.
+func fdump(cfg *Config, w io.Writer, a ...any) (int, error) {
+	return fdumpContext(context.Background(), cfg, w, a...)
+}
 
-	case reflect.Slice:
-		if v.IsNil() {
-			d.w.Write(nilAngleBytes)
-			break
-		}
-		if v.Len() == 0 && d.cfg.AbbreviateEmpty {
-			d.w.Write(emptyListBytes)
+// fdumpContext is fdump, which also stops once ctx is done, in which case
+// the error of ctx is returned.
+func fdumpContext(ctx context.Context, cfg *Config, w io.Writer, a ...any) (int, error) {
+	colors := colorsFor(cfg, w)
+	var ordinals pointerOrdinals
+	if cfg.PointerOrdinals {
+		ordinals = make(pointerOrdinals)
+	}
+	counter := newCountingWriter(ctx, cfg, w)
+	w = counter
+	for _, arg := range a {
+		if counter.stop() {
 			break
 		}
-
-		fallthrough
-
-	case reflect.Array:
-		if d.cfg.DumpListSquareBraces {
-			d.w.Write(openListNewlineBytes)
-		} else {
-			d.w.Write(openBraceNewlineBytes)
-		}
-
-		d.depth++
-		if (d.cfg.MaxDepth != 0) && (d.depth > d.cfg.MaxDepth) {
-			d.indent()
-			d.w.Write(maxNewlineBytes)
-		} else {
-			d.dumpSlice(v)
-		}
-		d.depth--
-		d.indent()
-		if d.cfg.DumpListSquareBraces {
-			d.w.Write(closeListBytes)
-		} else {
-			d.w.Write(closeBraceBytes)
-		}
-
-	case reflect.String:
-		d.w.Write([]byte(strconv.Quote(v.String())))
-
-	case reflect.Interface:
-		// The only time we should get here is for nil interfaces due to
-		// unpackValue calls.
-		if v.IsNil() {
-			d.w.Write(nilAngleBytes)
+		if arg == nil {
+			writeColor(w, colors.Type, interfaceBytes)
+			w.Write(spaceBytes)
+			writeColor(w, colors.Nil, nilAngleBytes)
+			w.Write(newlineBytes)
+			continue
 		}
 
-	case reflect.Ptr:
-		// Do nothing.  We should never get here since pointers have already
-		// been handled above.
-
-	case reflect.Map:
-		// nil maps should be indicated as different than empty maps
-		if v.IsNil() {
-			d.w.Write(nilAngleBytes)
-			break
+		dump := func(path string, names []string, v reflect.Value) {
+			d := newDumpState(cfg, w)
+			d.colors, d.ordinals, d.counter = colors, ordinals, counter
+			d.setPath(path, names)
+			d.dump(v)
+			d.w.Write(newlineBytes)
 		}
-		if v.Len() == 0 && d.cfg.AbbreviateEmpty {
-			d.w.Write(emptyBracesBytes)
-			break
-		}
-
-		d.w.Write(openBraceNewlineBytes)
-		d.depth++
-		if (d.cfg.MaxDepth != 0) && (d.depth > d.cfg.MaxDepth) {
-			d.indent()
-			d.w.Write(maxNewlineBytes)
-		} else {
-			numEntries := v.Len()
-			keys := v.MapKeys()
-			if d.cfg.SortKeys {
-				sortValues(keys, d.cfg)
-			}
-			for i, key := range keys {
-				d.dump(d.unpackValue(key))
-				d.w.Write(colonSpaceBytes)
-				d.ignoreNextIndent = true
-				d.dump(d.unpackValue(v.MapIndex(key)))
-				d.writeComma(i < (numEntries - 1))
-			}
+		if len(cfg.Paths) == 0 {
+			dump("", []string{""}, reflect.ValueOf(arg))
+			continue
 		}
-		d.depth--
-		d.indent()
-		d.w.Write(closeBraceBytes)
 
-	case reflect.Struct:
-		if v.NumField() == 0 && d.cfg.AbbreviateEmpty {
-			d.w.Write(emptyBracesBytes)
-			break
-		}
-		d.w.Write(openBraceNewlineBytes)
-		d.depth++
-		if (d.cfg.MaxDepth != 0) && (d.depth > d.cfg.MaxDepth) {
-			d.indent()
-			d.w.Write(maxNewlineBytes)
-		} else {
-			vt := v.Type()
-			numFields := v.NumField()
-			for i := 0; i < numFields; i++ {
-				vtf := vt.Field(i)
-				// StructField has an IsExported() method, but only in 1.17+.
-				if d.cfg.DisableUnexported && vtf.PkgPath != "" {
-					continue
+		// Output only the values selected by Paths, each preceded by its
+		// full path.
+		for _, path := range cfg.Paths {
+			err := selectPath(cfg, reflect.ValueOf(arg), path, func(path string, names []string, v reflect.Value) {
+				if counter.stop() {
+					return
 				}
-				d.indent()
-				d.w.Write([]byte(vtf.Name))
-				d.w.Write(colonSpaceBytes)
-				d.ignoreNextIndent = true
-				d.dump(d.unpackValue(v.Field(i)))
-				d.writeComma(i < (numFields - 1))
+				writeColor(w, colors.Field, []byte(path))
+				w.Write(colonSpaceBytes)
+				dump(path, names, v)
+			})
+			if perr, ok := err.(*pathError); ok && !counter.stop() {
+				writeColor(w, colors.Field, []byte(perr.path))
+				w.Write(colonSpaceBytes)
+				writeColor(w, colors.Marker, []byte("<"+perr.msg+">"))
+				w.Write(newlineBytes)
 			}
 		}
-		d.depth--
-		d.indent()
-		d.w.Write(closeBraceBytes)
-
-	case reflect.Uintptr:
-		printHexPtr(d.w, uintptr(v.Uint()))
-
-	case reflect.UnsafePointer, reflect.Chan:
-		printHexPtr(d.w, v.Pointer())
-
-	case reflect.Func:
-		if d.cfg.FuncSymbols {
-			fn := runtime.FuncForPC(v.Pointer())
-			if fn != nil {
-				name := fn.Name()
-				file, line := fn.FileLine(v.Pointer())
-				d.w.Write([]byte(filepath.Base(name)))
-				d.w.Write([]byte("["))
-				d.w.Write([]byte(filepath.Base(file)))
-				d.w.Write([]byte(":"))
-				printInt(d.w, int64(line), 10)
-				d.w.Write([]byte("]"))
-			}
-		} else {
-			printHexPtr(d.w, v.Pointer())
-		}
-
-	// There were not any other types at the time this code was written, but
-	// fall back to letting the default fmt package handle it in case any new
-	// types are added.
-	default:
-		if v.CanInterface() {
-			fmt.Fprintf(d.w, "%v", v.Interface())
-		} else {
-			fmt.Fprintf(d.w, "%v", v.String())
-		}
 	}
-}
-
-// writeComma emits a comma if hasMoreElements is true, or if trailing commas
-// are always enabled.
-func (d *dumpState) writeComma(hasMoreElements bool) {
-	if hasMoreElements || d.cfg.TrailingCommas {
-		d.w.Write(commaNewlineBytes)
-	} else {
-		d.w.Write(newlineBytes)
+	if marker := counter.truncatedBytes(); marker != nil {
+		writeColor(w, colors.Marker, marker)
+		w.Write(newlineBytes)
 	}
-}
-
-// fdump is a helper function to consolidate the logic from the various public
-// methods which take varying writers and config states.
-func fdump(cfg *Config, w io.Writer, a ...any) {
-	for _, arg := range a {
-		if arg == nil {
-			w.Write(interfaceBytes)
-			w.Write(spaceBytes)
-			w.Write(nilAngleBytes)
-			w.Write(newlineBytes)
-			continue
-		}
-
-		d := dumpState{w: w, cfg: cfg}
-		d.pointers = make(map[uintptr]int)
-		d.dump(reflect.ValueOf(arg))
-		d.w.Write(newlineBytes)
+	if counter.err != nil {
+		return counter.n, counter.err
 	}
+	return counter.n, counter.canceled
 }
 
 // Fdump formats and displays the passed arguments to io.Writer w.  It formats
@@ -520,6 +441,24 @@ func Fdump(w io.Writer, a ...any) {
 	fdump(&Default, w, a...)
 }
 
+// FdumpErr formats and displays the passed arguments to io.Writer w exactly the
+// same as Fdump.  It returns the number of bytes written and any write error
+// encountered, which stops the output.
+func FdumpErr(w io.Writer, a ...any) (n int, err error) {
+	return fdump(&Default, w, a...)
+}
+
+// FdumpContext formats and displays the passed arguments to io.Writer w exactly
+// the same as Fdump, checking periodically whether ctx is done, including
+// while waiting for Error and String methods to return.  Once it is, the
+// output is cut short with the marker <truncated after N bytes: ERR> and the
+// error of ctx is returned.  Any write error is returned instead, as for
+// FdumpErr.
+func FdumpContext(ctx context.Context, w io.Writer, a ...any) error {
+	_, err := fdumpContext(ctx, &Default, w, a...)
+	return err
+}
+
 // Sdump returns a string with the passed arguments formatted exactly the same
 // as Dump.
 func Sdump(a ...any) string {
@@ -1087,7 +1087,7 @@ func TestDumpUnexportedFields(t *testing.T) {
 	pvAddr := fmt.Sprintf("%p", &pv)
 	vt := "spew_test.s"
 	vt2 := "int8"
-	vs := "{\n ExportedVarA: (" + vt2 + ") 10,\n ExportedVarB: (" + vt2 + ") 20,\n}"
+	vs := "{\n ExportedVarA: (" + vt2 + ") 10,\n ExportedVarB: (" + vt2 + ") 20\n}"
 
 	addDumpTest(v, "("+vt+") "+vs+"\n")
 	addDumpTest(pv, "(*"+vt+")("+vAddr+")("+vs+")\n")
@@ -0,0 +1,89 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// errFull is returned by limitedWriter once it is full.
+var errFull = errors.New("writer full")
+
+// limitedWriter is an io.Writer which accepts at most max bytes, after which
+// it fails every write and counts the failed writes.
+type limitedWriter struct {
+	buf    bytes.Buffer
+	max    int
+	failed int
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if room := w.max - w.buf.Len(); len(p) > room {
+		w.failed++
+		if room < 0 {
+			room = 0
+		}
+		w.buf.Write(p[:room])
+		return room, errFull
+	}
+	return w.buf.Write(p)
+}
+
+// fdumpErrTest is used to describe a test to be performed against FdumpErr.
+type fdumpErrTest struct {
+	line string // use line() to fill this
+	max  int
+	in   any
+	want string
+	err  error
+}
+
+// TestFdumpErr executes all of the tests described by fdumpErrTests.
+func TestFdumpErr(t *testing.T) {
+	cfg := &spew.Config{Indent: " ", DisableCapacities: true}
+
+	tests := []fdumpErrTest{
+		{line(), 100, []int{1, 2}, "([]int) (len=2) {\n (int) 1,\n (int) 2\n}\n", nil},
+		{line(), 20, []int{1, 2}, "([]int) (len=2) {\n (", errFull},
+		{line(), 0, []int{1, 2}, "", errFull},
+		{line(), 20, map[string]string{"a": "b", "c": "d"}, "(map[string]string) ", errFull},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		w := &limitedWriter{max: test.max}
+		n, err := cfg.FdumpErr(w, test.in)
+		if s := w.buf.String(); s != test.want || n != len(test.want) || err != test.err {
+			t.Errorf("testcase on line %s:\n got: %d %v %s\nwant: %d %v %s",
+				test.line, n, err, s, len(test.want), test.err, test.want)
+		}
+		if w.failed > 1 {
+			t.Errorf("testcase on line %s: %d writes after the first error",
+				test.line, w.failed-1)
+		}
+	}
+
+	// The remaining arguments are not output after an error.
+	w := &limitedWriter{max: 10}
+	if n, err := spew.FdumpErr(w, 1, 2, 3); n != 10 || err != errFull || w.failed != 1 {
+		t.Errorf("FdumpErr: got %d %v with %d failed writes", n, err, w.failed)
+	}
+}
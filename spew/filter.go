@@ -0,0 +1,116 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"path"
+	"reflect"
+	"strings"
+)
+
+// globMatch reports whether names, the names of a struct field or map entry
+// and those of the values containing it, match the glob pattern pat, split at
+// dots.  It also reports whether the names of a value within it could match.
+// The part ** matches any number of names, while other parts match a single
+// name with path.Match.
+func globMatch(pat, names []string) (match, prefix bool) {
+	if len(names) == 0 {
+		for _, part := range pat {
+			if part != "**" {
+				return false, true
+			}
+		}
+		return true, len(pat) > 0
+	}
+	if len(pat) == 0 {
+		return false, false
+	}
+	if pat[0] == "**" {
+		m1, p1 := globMatch(pat[1:], names)
+		m2, p2 := globMatch(pat, names[1:])
+		return m1 || m2, p1 || p2
+	}
+	if ok, _ := path.Match(pat[0], names[0]); !ok {
+		return false, false
+	}
+	return globMatch(pat[1:], names[1:])
+}
+
+// matchFields returns whether names match any of patterns, and whether the
+// names of a value within it could.
+func matchFields(patterns []string, names []string) (match, prefix bool) {
+	for _, pat := range patterns {
+		m, p := globMatch(strings.Split(pat, "."), names)
+		match = match || m
+		prefix = prefix || p
+	}
+	return match, prefix
+}
+
+// hasFields reports whether values of type t can contain struct fields or map
+// entries, directly or within pointers, arrays, slices and maps.
+func hasFields(t reflect.Type) bool {
+	seen := make(map[reflect.Type]bool)
+	for !seen[t] {
+		seen[t] = true
+		switch t.Kind() {
+		case reflect.Struct, reflect.Map, reflect.Interface:
+			return true
+		case reflect.Ptr, reflect.Array, reflect.Slice:
+			t = t.Elem()
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// filterName reports whether the struct field or string map entry name, of
+// type t, should be left out according to the IncludeFields and ExcludeFields
+// options, and whether everything within it is included.  With IncludeFields,
+// values which do not match are still walked when values within them could,
+// so that the way to those is output.
+func (w *walker) filterName(name string, t reflect.Type) (skip, included bool) {
+	cfg := w.cfg
+	if len(cfg.IncludeFields) == 0 && len(cfg.ExcludeFields) == 0 {
+		return false, false
+	}
+	names := appendName(w.names, name)
+	if match, _ := matchFields(cfg.ExcludeFields, names); match {
+		return true, false
+	}
+	if len(cfg.IncludeFields) == 0 || w.included {
+		return false, true
+	}
+	match, prefix := matchFields(cfg.IncludeFields, names)
+	if match {
+		return false, true
+	}
+	return !prefix || !hasFields(t), false
+}
+
+// setPath starts the walk at path, where names are the names matched by the
+// IncludeFields and ExcludeFields options.  Everything is included when
+// IncludeFields matches names or those of a value containing it.
+func (w *walker) setPath(path string, names []string) {
+	w.path, w.names, w.included = path, names, false
+	for i := range names {
+		if match, _ := matchFields(w.cfg.IncludeFields, names[:i+1]); match {
+			w.included = true
+		}
+	}
+}
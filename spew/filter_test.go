@@ -0,0 +1,106 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+type filterMeta struct {
+	Name    string
+	Managed []string
+	Labels  map[string]string
+}
+
+type filterItem struct {
+	Name        string
+	XXX_unrecog []byte
+	Env         map[string]string
+}
+
+type filterObject struct {
+	Meta          filterMeta
+	Items         []*filterItem
+	XXX_sizecache int
+}
+
+// filterConfig returns the Config the filter tests use with include and
+// exclude as its IncludeFields and ExcludeFields options.
+func filterConfig(include, exclude []string) *spew.Config {
+	return &spew.Config{Indent: " ", DisableCapacities: true, DisablePointerAddresses: true,
+		SortKeys: true, IncludeFields: include, ExcludeFields: exclude}
+}
+
+// TestFilterFields executes all of the tests described by filterTests.
+func TestFilterFields(t *testing.T) {
+	obj := filterObject{
+		Meta: filterMeta{
+			Name:    "obj",
+			Managed: []string{"m"},
+			Labels:  map[string]string{"app": "web", "secret": "x"},
+		},
+		Items: []*filterItem{{
+			Name:        "i",
+			XXX_unrecog: []byte{1},
+			Env:         map[string]string{"K": "V"},
+		}},
+	}
+
+	tests := []outputTest{
+		{line(), filterConfig(nil, []string{"*.Meta.Managed", "**.XXX_*", "*.Meta.Labels.secret"}), "", obj,
+			"(spew_test.filterObject) {\n" +
+				" Meta: (spew_test.filterMeta) {\n" +
+				"  Name: (string) (len=3) \"obj\",\n" +
+				"  Labels: (map[string]string) (len=2) {\n" +
+				"   (string) (len=3) \"app\": (string) (len=3) \"web\"\n" +
+				"  }\n" +
+				" },\n" +
+				" Items: ([]*spew_test.filterItem) (len=1) {\n" +
+				"  (*spew_test.filterItem)({\n" +
+				"   Name: (string) (len=1) \"i\",\n" +
+				"   Env: (map[string]string) (len=1) {\n" +
+				"    (string) (len=1) \"K\": (string) (len=1) \"V\"\n" +
+				"   }\n" +
+				"  })\n" +
+				" }\n" +
+				"}\n"},
+		{line(), filterConfig([]string{"**.Env"}, nil), "", obj,
+			"(spew_test.filterObject) {\n" +
+				" Meta: (spew_test.filterMeta) {\n" +
+				"  Labels: (map[string]string) (len=2) {\n" +
+				"  }\n" +
+				" },\n" +
+				" Items: ([]*spew_test.filterItem) (len=1) {\n" +
+				"  (*spew_test.filterItem)({\n" +
+				"   Env: (map[string]string) (len=1) {\n" +
+				"    (string) (len=1) \"K\": (string) (len=1) \"V\"\n" +
+				"   }\n" +
+				"  })\n" +
+				" }\n" +
+				"}\n"},
+		{line(), filterConfig([]string{"*.Meta"}, []string{"**.Labels"}), "%+v", obj,
+			"{Meta:{Name:obj Managed:[m]}}"},
+		{line(), filterConfig([]string{"*.Meta.Labels.app"}, nil), "%v", obj,
+			"{{map[app:web]}}"},
+		{line(), filterConfig(nil, []string{"*.secret"}), "%v", map[string]int{"secret": 1, "x": 2},
+			"map[x:2]"},
+	}
+
+	runOutputTests(t, tests)
+}
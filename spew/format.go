@@ -18,10 +18,10 @@ package spew
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-	"path/filepath"
+	"io"
 	"reflect"
-	"runtime"
 	"strconv"
 	"strings"
 )
@@ -32,14 +32,40 @@ const supportedFlags = "0-+# "
 // formatState implements the fmt.Formatter interface and contains information
 // about the state of a formatting operation.  The NewFormatter function can
 // be used to get a new Formatter which can be used directly as arguments
-// in standard fmt package printing calls.
+// in standard fmt package printing calls.  It renders the values reported by
+// its walker in the inline Formatter style.
 type formatState struct {
-	value          any
-	fs             fmt.State
-	depth          int
-	pointers       map[uintptr]int
-	ignoreNextType bool
-	cfg            *Config
+	*walker
+	value  any
+	fs     fmt.State
+	colors ColorScheme
+
+	// ordinals is only set when the PointerOrdinals option is set.
+	ordinals pointerOrdinals
+}
+
+// newFormatState returns a formatState which formats to fs with cfg.
+func newFormatState(cfg *Config, fs fmt.State) *formatState {
+	f := &formatState{fs: fs}
+	f.walker = newWalker(cfg, f)
+	return f
+}
+
+// format formats the value v.
+func (f *formatState) format(v reflect.Value) {
+	f.walk(v, slotTop)
+}
+
+// countingState is a fmt.State which counts the bytes written to it for the
+// MaxBytes option.
+type countingState struct {
+	fmt.State
+	counter *countingWriter
+}
+
+// Write writes p to the underlying fmt.State, counting the bytes written.
+func (s countingState) Write(p []byte) (int, error) {
+	return s.counter.Write(p)
 }
 
 // buildDefaultFormat recreates the original format string without precision
@@ -88,327 +114,201 @@ func (f *formatState) constructOrigFormat(verb rune) (format string) {
 	return format
 }
 
-// unpackValue returns values inside of non-nil interfaces when possible and
-// ensures that types for values which have been unpacked from an interface
-// are displayed when the show types flag is also set.
-// This is useful for data types like structs, arrays, slices, and maps which
-// can contain varying types packed inside an interface.
-func (f *formatState) unpackValue(v reflect.Value) reflect.Value {
-	if v.Kind() == reflect.Interface {
-		f.ignoreNextType = false
-		if !v.IsNil() {
-			v = v.Elem()
-		}
+// showTypes reports whether to display the type of the value in slot s, which
+// is only done for the %#v verb.  The types of elements, map keys and values,
+// and the values pointers point to are implied by the type of the collection
+// or pointer, unless they were unpacked from an interface.
+func (f *formatState) showTypes(s slot, iface bool) bool {
+	if !f.fs.Flag('#') || f.cfg.DisableTypes {
+		return false
+	}
+	return iface || s == slotTop || s == slotField
+}
+
+func (f *formatState) writer() io.Writer {
+	return f.fs
+}
+
+func (f *formatState) context() Context {
+	return f.methodContext(true)
+}
+
+func (f *formatState) header(v reflect.Value, s slot, iface bool) {
+	if f.showTypes(s, iface) {
+		writeColor(f.fs, f.colors.Type, []byte("("+v.Type().String()+")"))
 	}
-	return v
 }
 
-// formatPtr handles formatting of pointers by indirecting them as necessary.
-func (f *formatState) formatPtr(v reflect.Value) {
-	// Display nil if top level pointer is nil.
-	showTypes := f.fs.Flag('#')
-	if v.IsNil() && (!showTypes || f.ignoreNextType) {
-		f.fs.Write(nilAngleBytes)
+// lengths does nothing, since lengths are implied by the number of items
+// shown inline.
+func (f *formatState) lengths(v reflect.Value) {}
+
+func (f *formatState) leaf(v reflect.Value) {
+	if printLeaf(f.fs, f.cfg, f.colors, v, f.hex) {
 		return
 	}
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		printString(f.fs, f.cfg, f.colors, s, f.cfg.QuoteStrings, f.counter.limit(f.cfg.MaxStringLen, len(s)))
 
-	// Remove pointers at or below the current depth from map used to detect
-	// circular refs.
-	for k, depth := range f.pointers {
-		if depth >= f.depth {
-			delete(f.pointers, k)
+	// There were not any other types at the time this code was written, but
+	// fall back to letting the default fmt package handle it if any get added.
+	default:
+		format := f.buildDefaultFormat()
+		if v.CanInterface() {
+			fmt.Fprintf(f.fs, format, v.Interface())
+		} else {
+			fmt.Fprintf(f.fs, format, v.String())
 		}
 	}
+}
 
-	// Keep list of all dereferenced pointers to possibly show later.
-	pointerChain := make([]uintptr, 0)
-
-	// Figure out how many levels of indirection there are by derferencing
-	// pointers and unpacking interfaces down the chain while detecting circular
-	// references.
-	nilFound := false
-	cycleFound := false
-	indirects := 0
-	ve := v
-	for ve.Kind() == reflect.Ptr {
-		if ve.IsNil() {
-			nilFound = true
-			break
-		}
-		indirects++
-		addr := ve.Pointer()
-		pointerChain = append(pointerChain, addr)
-		if pd, ok := f.pointers[addr]; ok && pd < f.depth {
-			cycleFound = true
-			indirects--
-			break
-		}
-		f.pointers[addr] = f.depth
+func (f *formatState) invalid() {
+	writeColor(f.fs, f.colors.Nil, invalidAngleBytes)
+}
 
-		ve = ve.Elem()
-		if ve.Kind() == reflect.Interface {
-			if ve.IsNil() {
-				nilFound = true
-				break
-			}
-			ve = ve.Elem()
-		}
+func (f *formatState) nilValue() {
+	writeColor(f.fs, f.colors.Nil, nilAngleBytes)
+}
+
+func (f *formatState) circular() {
+	writeColor(f.fs, f.colors.Marker, circularShortBytes)
+}
+
+func (f *formatState) maxDepth() {
+	writeColor(f.fs, f.colors.Marker, maxShortBytes)
+}
+
+func (f *formatState) marker(b []byte) {
+	writeColor(f.fs, f.colors.Marker, b)
+}
+
+// enterPointer displays the type or indirection level depending on flags,
+// followed by the addresses of the chain of pointers for the %+v verb.  A nil
+// pointer is displayed as just <nil> unless its type is shown.
+func (f *formatState) enterPointer(p *pointerInfo, s slot, iface bool) {
+	showTypes := f.showTypes(s, iface)
+	if len(p.chain) == 0 && p.nilFound && !showTypes {
+		return
 	}
 
 	// Display type or indirection level depending on flags.
-	if showTypes && !f.ignoreNextType {
+	if showTypes {
+		setColor(f.fs, f.colors.Type)
 		f.fs.Write(openParenBytes)
-		f.fs.Write(bytes.Repeat(asteriskBytes, indirects))
-		f.fs.Write([]byte(ve.Type().String()))
+		f.fs.Write(bytes.Repeat(asteriskBytes, p.indirects))
+		f.fs.Write([]byte(p.ve.Type().String()))
 		f.fs.Write(closeParenBytes)
+		resetColor(f.fs, f.colors.Type)
 	} else {
-		if nilFound || cycleFound {
-			indirects += strings.Count(ve.Type().String(), "*")
+		indirects := p.indirects
+		if p.nilFound || p.cycleFound || p.sharedFound {
+			indirects += strings.Count(p.ve.Type().String(), "*")
 		}
 		f.fs.Write(openAngleBytes)
-		f.fs.Write([]byte(strings.Repeat("*", indirects)))
+		f.fs.Write(bytes.Repeat(asteriskBytes, indirects))
 		f.fs.Write(closeAngleBytes)
 	}
 
 	// Display pointer information depending on flags.
-	if f.fs.Flag('+') && (len(pointerChain) > 0) {
+	if f.fs.Flag('+') && !f.cfg.DisablePointerAddresses && len(p.chain) > 0 {
 		f.fs.Write(openParenBytes)
-		for i, addr := range pointerChain {
+		for i, addr := range p.chain {
 			if i > 0 {
 				f.fs.Write(pointerChainBytes)
 			}
-			printHexPtr(f.fs, addr)
+			setColor(f.fs, f.colors.Address)
+			printPtr(f.fs, addr, f.ordinals)
+			resetColor(f.fs, f.colors.Address)
 		}
 		f.fs.Write(closeParenBytes)
 	}
+}
 
-	// Display dereferenced value.
-	switch {
-	case nilFound:
-		f.fs.Write(nilAngleBytes)
+func (f *formatState) leavePointer() {}
 
-	case cycleFound:
-		f.fs.Write(circularShortBytes)
+func (f *formatState) empty(v reflect.Value) {
+	f.open(v)
+	f.close(v)
+}
 
+func (f *formatState) open(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Map:
+		f.fs.Write(openMapBytes)
+	case reflect.Struct:
+		f.fs.Write(openBraceBytes)
 	default:
-		f.ignoreNextType = true
-		f.format(ve)
+		f.fs.Write(openBracketBytes)
 	}
 }
 
-// format is the main workhorse for providing the Formatter interface.  It
-// uses the passed reflect value to figure out what kind of object we are
-// dealing with and formats it appropriately.  It is a recursive function,
-// however circular data structures are detected and handled properly.
-func (f *formatState) format(v reflect.Value) {
-	// Handle invalid reflect values immediately.
-	kind := v.Kind()
-	if kind == reflect.Invalid {
-		f.fs.Write(invalidAngleBytes)
-		return
+func (f *formatState) close(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Map:
+		f.fs.Write(closeMapBytes)
+	case reflect.Struct:
+		f.fs.Write(closeBraceBytes)
+	default:
+		f.fs.Write(closeBracketBytes)
 	}
+}
 
-	// Handle pointers specially.
-	if kind == reflect.Ptr {
-		f.formatPtr(v)
-		return
-	}
+// bytes does nothing, since byte arrays and slices are formatted like other
+// arrays and slices.
+func (f *formatState) bytes(v reflect.Value) bool {
+	return false
+}
 
-	// Print type information unless already handled elsewhere.
-	if !f.ignoreNextType && f.fs.Flag('#') {
-		f.fs.Write(openParenBytes)
-		f.fs.Write([]byte(v.Type().String()))
-		f.fs.Write(closeParenBytes)
+// beginItem writes the separator between the elements of lists, maps, and
+// structs.
+func (f *formatState) beginItem(first bool) {
+	if first {
+		return
 	}
-	f.ignoreNextType = false
-
-	// Call Stringer/error interfaces if they exist and the handle methods
-	// flag is enabled.
-	if !f.cfg.DisableMethods {
-		if (kind != reflect.Invalid) && (kind != reflect.Interface) {
-			if handled := handleMethods(f.cfg, f.fs, v); handled {
-				return
-			}
-		}
+	if f.cfg.Commas {
+		f.fs.Write(commaBytes)
+	} else {
+		f.fs.Write(spaceBytes)
 	}
+}
 
-	switch kind {
-	case reflect.Invalid:
-		// Do nothing.  We should never get here since invalid has already
-		// been handled above.
-
-	case reflect.Bool:
-		printBool(f.fs, v.Bool())
-
-	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
-		printInt(f.fs, v.Int(), 10)
-
-	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
-		printUint(f.fs, v.Uint(), 10)
-
-	case reflect.Float32:
-		printFloat(f.fs, v.Float(), 32)
-
-	case reflect.Float64:
-		printFloat(f.fs, v.Float(), 64)
-
-	case reflect.Complex64:
-		printComplex(f.fs, v.Complex(), 32)
-
-	case reflect.Complex128:
-		printComplex(f.fs, v.Complex(), 64)
-
-	case reflect.Slice:
-		if v.IsNil() {
-			f.fs.Write(nilAngleBytes)
-			break
-		}
-		fallthrough
-
-	case reflect.Array:
-		f.fs.Write(openBracketBytes)
-		f.depth++
-		if (f.cfg.MaxDepth != 0) && (f.depth > f.cfg.MaxDepth) {
-			f.fs.Write(maxShortBytes)
-		} else {
-			numEntries := v.Len()
-			for i := 0; i < numEntries; i++ {
-				if i > 0 {
-					if f.cfg.Commas {
-						f.fs.Write(commaBytes)
-					} else {
-						f.fs.Write(spaceBytes)
-					}
-				}
-				f.ignoreNextType = true
-				f.format(f.unpackValue(v.Index(i)))
-			}
-		}
-		f.depth--
-		f.fs.Write(closeBracketBytes)
-
-	case reflect.String:
-		s := v.String()
-		if f.cfg.QuoteStrings {
-			s = strconv.Quote(s)
-		}
-		f.fs.Write([]byte(s))
-
-	case reflect.Interface:
-		// The only time we should get here is for nil interfaces due to
-		// unpackValue calls.
-		if v.IsNil() {
-			f.fs.Write(nilAngleBytes)
-		}
-
-	case reflect.Ptr:
-		// Do nothing.  We should never get here since pointers have already
-		// been handled above.
-
-	case reflect.Map:
-		// nil maps should be indicated as different than empty maps
-		if v.IsNil() {
-			f.fs.Write(nilAngleBytes)
-			break
-		}
-
-		f.fs.Write(openMapBytes)
-		f.depth++
-		if (f.cfg.MaxDepth != 0) && (f.depth > f.cfg.MaxDepth) {
-			f.fs.Write(maxShortBytes)
-		} else {
-			keys := v.MapKeys()
-			if f.cfg.SortKeys {
-				sortValues(keys, f.cfg)
-			}
-			for i, key := range keys {
-				if i > 0 {
-					if f.cfg.Commas {
-						f.fs.Write(commaBytes)
-					} else {
-						f.fs.Write(spaceBytes)
-					}
-				}
-				f.ignoreNextType = true
-				f.format(f.unpackValue(key))
-				f.fs.Write(colonBytes)
-				f.ignoreNextType = true
-				f.format(f.unpackValue(v.MapIndex(key)))
-			}
-		}
-		f.depth--
-		f.fs.Write(closeMapBytes)
+// endItems writes a trailing comma after the last item if trailing commas
+// are enabled along with commas between items.
+func (f *formatState) endItems() {
+	if f.cfg.TrailingCommas && f.cfg.Commas {
+		f.fs.Write(commaBytes)
+	}
+}
 
-	case reflect.Struct:
-		numFields := v.NumField()
-		f.fs.Write(openBraceBytes)
-		f.depth++
-		if (f.cfg.MaxDepth != 0) && (f.depth > f.cfg.MaxDepth) {
-			f.fs.Write(maxShortBytes)
-		} else {
-			vt := v.Type()
-			for i := 0; i < numFields; i++ {
-				vtf := vt.Field(i)
-				// StructField has an IsExported() method, but only in 1.17+.
-				if f.cfg.DisableUnexported && vtf.PkgPath != "" {
-					continue
-				}
-				if i > 0 {
-					if f.cfg.Commas {
-						f.fs.Write(commaBytes)
-					} else {
-						f.fs.Write(spaceBytes)
-					}
-				}
-				if f.fs.Flag('+') || f.fs.Flag('#') {
-					f.fs.Write([]byte(vtf.Name))
-					f.fs.Write(colonBytes)
-				}
-				f.format(f.unpackValue(v.Field(i)))
-			}
-		}
-		f.depth--
-		f.fs.Write(closeBraceBytes)
+func (f *formatState) more(n int) {
+	writeColor(f.fs, f.colors.Marker, moreBytes(n))
+}
 
-	case reflect.Uintptr:
-		printHexPtr(f.fs, uintptr(v.Uint()))
-
-	case reflect.UnsafePointer, reflect.Chan:
-		printHexPtr(f.fs, v.Pointer())
-
-	case reflect.Func:
-		if f.cfg.FuncSymbols {
-			fn := runtime.FuncForPC(v.Pointer())
-			if fn != nil {
-				name := fn.Name()
-				file, line := fn.FileLine(v.Pointer())
-				f.fs.Write([]byte(filepath.Base(name)))
-				f.fs.Write([]byte("["))
-				f.fs.Write([]byte(filepath.Base(file)))
-				f.fs.Write([]byte(":"))
-				printInt(f.fs, int64(line), 10)
-				f.fs.Write([]byte("]"))
-			}
-		} else {
-			printHexPtr(f.fs, v.Pointer())
-		}
+func (f *formatState) element(i int, v reflect.Value) {}
 
-	// There were not any other types at the time this code was written, but
-	// fall back to letting the default fmt package handle it if any get added.
-	default:
-		format := f.buildDefaultFormat()
-		if v.CanInterface() {
-			fmt.Fprintf(f.fs, format, v.Interface())
-		} else {
-			fmt.Fprintf(f.fs, format, v.String())
-		}
+// field writes the names of struct fields for the %+v and %#v verbs.
+func (f *formatState) field(name string, v reflect.Value) {
+	if f.fs.Flag('+') || f.fs.Flag('#') {
+		writeColor(f.fs, f.colors.Field, []byte(name))
+		f.fs.Write(colonBytes)
 	}
 }
 
+func (f *formatState) key(k, v reflect.Value) {
+	f.walk(k, slotKey)
+	f.fs.Write(colonBytes)
+}
+
 // Format satisfies the fmt.Formatter interface. See NewFormatter for usage
 // details.
 func (f *formatState) Format(fs fmt.State, verb rune) {
 	f.fs = fs
+	f.walker = newWalker(f.cfg, f)
+	if f.cfg.PointerOrdinals {
+		f.ordinals = make(pointerOrdinals)
+	}
 
 	// Use standard formatting for verbs that are not v.
 	if verb != 'v' {
@@ -418,22 +318,58 @@ func (f *formatState) Format(fs fmt.State, verb rune) {
 	}
 
 	if f.value == nil {
-		if fs.Flag('#') {
-			fs.Write(interfaceBytes)
+		if fs.Flag('#') && !f.cfg.DisableTypes {
+			writeColor(fs, f.colors.Type, interfaceBytes)
 		}
-		fs.Write(nilAngleBytes)
+		writeColor(fs, f.colors.Nil, nilAngleBytes)
 		return
 	}
 
+	if f.cfg.MaxBytes > 0 {
+		f.counter = newCountingWriter(context.Background(), f.cfg, fs)
+		f.fs = countingState{State: fs, counter: f.counter}
+	}
 	f.format(reflect.ValueOf(f.value))
+	if marker := f.counter.truncatedBytes(); marker != nil {
+		fs.Write(spaceBytes)
+		writeColor(fs, f.colors.Marker, marker)
+	}
+}
+
+// stringState implements a minimal fmt.State over a buffer so the Formatter
+// can be used to render values directly from reflection.
+type stringState struct {
+	bytes.Buffer
+	flags string
+}
+
+func (ss *stringState) Width() (int, bool) {
+	return 0, false
+}
+
+func (ss *stringState) Precision() (int, bool) {
+	return 0, false
+}
+
+func (ss *stringState) Flag(c int) bool {
+	return strings.ContainsRune(ss.flags, rune(c))
+}
+
+// sprintValue returns v formatted inline the same way the Formatter would
+// format it with the passed flags, such as "#" for %#v.
+func sprintValue(cfg *Config, v reflect.Value, flags string) string {
+	ss := &stringState{flags: flags}
+	newFormatState(cfg, ss).format(v)
+	return ss.String()
 }
 
 // newFormatter is a helper function to consolidate the logic from the various
-// public methods which take varying config states.
-func newFormatter(cfg *Config, v any) fmt.Formatter {
-	fs := &formatState{value: v, cfg: cfg}
-	fs.pointers = make(map[uintptr]int)
-	return fs
+// public methods which take varying config states.  The output is destined for
+// w, which is nil if it isn't known.
+func newFormatter(cfg *Config, w io.Writer, v any) fmt.Formatter {
+	f := newFormatState(cfg, nil)
+	f.value, f.colors = v, colorsFor(cfg, w)
+	return f
 }
 
 // NewFormatter returns a custom formatter that satisfies the fmt.Formatter
@@ -452,5 +388,5 @@ func newFormatter(cfg *Config, v any) fmt.Formatter {
 // use of the custom formatter by calling one of the convenience functions such as
 // Printf, Println, or Fprintf.
 func NewFormatter(v any) fmt.Formatter {
-	return newFormatter(&Default, v)
+	return newFormatter(&Default, nil, v)
 }
@@ -0,0 +1,527 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// goState contains information about the state of a Go syntax rendering
+// operation.
+type goState struct {
+	cfg    *Config
+	indent string
+	depth  int
+
+	// refs counts how many times each pointer is reached.  Pointers which
+	// are reached more than once, including through a cycle, are bound to
+	// helper variables so that the output preserves the sharing.
+	refs map[uintptr]int
+
+	// names holds the helper variable bound to each shared pointer, and
+	// done records which of those have been fully declared.  A pointer
+	// which is named but not done is still being rendered, so a reference
+	// to it closes a cycle.
+	names map[uintptr]string
+	done  map[uintptr]bool
+
+	// decls and fixups hold the helper variable declarations and the
+	// assignments needed to close cycles, in the order they must appear.
+	decls  []string
+	fixups []string
+
+	// hex is set while rendering a field with the hex spew struct tag.
+	hex bool
+
+	// spewPath is the path of the value being rendered in the form Dump
+	// uses, such as .Items[3].Name, which the Redact option is matched
+	// against.
+	spewPath string
+}
+
+// lineIndent returns the indentation for the current depth.
+func (g *goState) lineIndent() string {
+	return strings.Repeat(g.indent, g.depth)
+}
+
+// typeName returns the name of t in a form which can be used as the operand
+// of a conversion.
+func typeName(t reflect.Type) string {
+	s := t.String()
+	if strings.HasPrefix(s, "*") || strings.HasPrefix(s, "func") || strings.HasPrefix(s, "<-") {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+// isDefaultType returns whether t is the type an untyped constant of the same
+// kind defaults to, in which case no conversion is needed.
+func isDefaultType(t reflect.Type) bool {
+	if t.PkgPath() != "" {
+		return false
+	}
+	switch t.Name() {
+	case "bool", "int", "float64", "complex128", "string":
+		return true
+	}
+	return false
+}
+
+// floatLiteral returns a float constant which is untyped-float even when it
+// has an integral value.
+func floatLiteral(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "math.NaN()"
+	case math.IsInf(f, 1):
+		return "math.Inf(1)"
+	case math.IsInf(f, -1):
+		return "math.Inf(-1)"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, bitSize)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	return s
+}
+
+// child returns the path through which a child of a value at base can be
+// assigned, or an empty string if it can't.
+func child(base, suffix string) string {
+	if base == "" {
+		return ""
+	}
+	return base + suffix
+}
+
+// render returns v as a Go expression.  If typed is set, the expression must
+// have exactly the type of v, as in an interface or at the top level.  Path is
+// an assignable expression which refers to v, or an empty string if there is
+// none; it is used to close cycles once all helper variables are declared.
+func (g *goState) render(v reflect.Value, typed bool, path string) string {
+	kind := v.Kind()
+	switch kind {
+	case reflect.Invalid:
+		return "nil"
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		// The contents of an interface can be replaced as a whole, but
+		// can only be assigned into through a type asserted pointer.
+		ve := v.Elem()
+		if ve.Kind() == reflect.Ptr {
+			return g.renderPtr(ve, true, path, child(path, ".("+ve.Type().String()+")"))
+		}
+		return g.render(ve, true, "")
+
+	case reflect.Ptr:
+		return g.renderPtr(v, typed, path, path)
+
+	case reflect.Bool:
+		return g.convert(v, typed, strconv.FormatBool(v.Bool()))
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		return g.convert(v, typed, formatInt(v, g.hex))
+
+	case reflect.Uintptr:
+		return g.convert(v, typed, "0x"+strconv.FormatUint(v.Uint(), 16))
+
+	case reflect.Float32:
+		return g.convert(v, typed, floatLiteral(v.Float(), 32))
+
+	case reflect.Float64:
+		return g.convert(v, typed, floatLiteral(v.Float(), 64))
+
+	case reflect.Complex64, reflect.Complex128:
+		bitSize := 64
+		if kind == reflect.Complex64 {
+			bitSize = 32
+		}
+		c := v.Complex()
+		lit := "complex(" + floatLiteral(real(c), bitSize) + ", " + floatLiteral(imag(c), bitSize) + ")"
+		return g.convert(v, typed, lit)
+
+	case reflect.String:
+		if redactString(g.cfg, v) {
+			return g.convert(v, typed, `"" /* `+string(redactedLen(v))+` */`)
+		}
+		return g.convert(v, typed, strconv.Quote(v.String()))
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return g.convert(v, typed, "nil")
+		}
+		// Byte slices are much more readable as string conversions.
+		if v.Type().Elem() == uint8Type {
+			return v.Type().String() + "(" + strconv.Quote(string(v.Bytes())) + ")"
+		}
+		return g.renderList(v, path)
+
+	case reflect.Array:
+		return g.renderList(v, path)
+
+	case reflect.Map:
+		if v.IsNil() {
+			return g.convert(v, typed, "nil")
+		}
+		return g.renderMap(v, path)
+
+	case reflect.Struct:
+		return g.renderStruct(v, path)
+
+	case reflect.Chan:
+		if v.IsNil() {
+			return g.convert(v, typed, "nil")
+		}
+		return "make(" + v.Type().String() + ", " + strconv.Itoa(v.Cap()) + ")"
+
+	case reflect.Func:
+		if v.IsNil() {
+			return g.convert(v, typed, "nil")
+		}
+		// Functions can't be reconstructed, so leave a note about which
+		// one was there.
+		note := "func"
+		if fn := runtime.FuncForPC(v.Pointer()); fn != nil {
+			note = filepath.Base(fn.Name())
+		}
+		return g.convert(v, typed, "nil /* "+note+" */")
+
+	case reflect.UnsafePointer:
+		if v.Pointer() == 0 {
+			return g.convert(v, typed, "nil")
+		}
+		var buf bytes.Buffer
+		printHexPtr(&buf, v.Pointer())
+		return g.convert(v, typed, "nil /* "+buf.String()+" */")
+	}
+
+	// There were not any other types at the time this code was written.
+	return "nil /* " + v.Type().String() + " */"
+}
+
+// convert wraps a literal in a conversion to the type of v when the context
+// requires an exact type and the literal wouldn't otherwise have it.
+func (g *goState) convert(v reflect.Value, typed bool, lit string) string {
+	if !typed || isDefaultType(v.Type()) && lit != "nil" {
+		return lit
+	}
+	return typeName(v.Type()) + "(" + lit + ")"
+}
+
+// renderPtr returns a pointer as either a helper variable, if it is shared, or
+// an inline &T{} expression.  Path is where the pointer itself can be
+// assigned, and base is the expression through which its pointee can be.
+func (g *goState) renderPtr(v reflect.Value, typed bool, path, base string) string {
+	if v.IsNil() {
+		return g.convert(v, typed, "nil")
+	}
+
+	addr := v.Pointer()
+	if g.refs[addr] < 2 {
+		return g.renderAddr(v, base)
+	}
+
+	if name, ok := g.names[addr]; ok {
+		if g.done[addr] {
+			return name
+		}
+
+		// The pointee is still being rendered, so this reference closes
+		// a cycle.  Leave it nil and assign it once everything has been
+		// declared.
+		if path == "" {
+			return "nil /* cycle to " + name + " */"
+		}
+		g.fixups = append(g.fixups, path+" = "+name)
+		return "nil"
+	}
+
+	// Helper variables are declared at the top level of the function body,
+	// regardless of where they are first reached.
+	name := "p" + strconv.Itoa(len(g.names)+1)
+	g.names[addr] = name
+	depth := g.depth
+	g.depth = 1
+	decl := name + " := " + g.renderAddr(v, name)
+	g.depth = depth
+	g.decls = append(g.decls, decl)
+	g.done[addr] = true
+	return name
+}
+
+// renderAddr returns an expression which evaluates to a new pointer to a copy
+// of the value v points to.
+func (g *goState) renderAddr(v reflect.Value, path string) string {
+	ve := v.Elem()
+	switch ve.Kind() {
+	case reflect.Struct:
+		// Fields can be selected through the pointer directly.
+		return "&" + g.render(ve, true, path)
+
+	case reflect.Array, reflect.Slice, reflect.Map:
+		// These are only rendered as composite literals when they are
+		// not nil and not byte slices.
+		composite := ve.Kind() == reflect.Array ||
+			!ve.IsNil() && !(ve.Kind() == reflect.Slice && ve.Type().Elem() == uint8Type)
+		if composite {
+			return "&" + g.render(ve, true, derefPath(path))
+		}
+	}
+
+	// Only composite literals can have their address taken directly, so
+	// anything else is wrapped in a single element slice.
+	return "&[]" + v.Type().Elem().String() + "{" + g.render(ve, false, derefPath(path)) + "}[0]"
+}
+
+// derefPath returns the path of the value a pointer at path points to.
+func derefPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return "(*" + path + ")"
+}
+
+// renderList returns a slice or array as a composite literal.
+func (g *goState) renderList(v reflect.Value, path string) string {
+	numEntries := v.Len()
+	if numEntries == 0 {
+		return v.Type().String() + "{}"
+	}
+
+	typed := v.Type().Elem().Kind() == reflect.Interface
+	var buf strings.Builder
+	buf.WriteString(v.Type().String())
+	buf.WriteString("{\n")
+	g.depth++
+	spewPath := g.spewPath
+	for i := 0; i < numEntries; i++ {
+		g.spewPath = indexPath(spewPath, i)
+		buf.WriteString(g.lineIndent())
+		buf.WriteString(g.render(v.Index(i), typed, child(path, "["+strconv.Itoa(i)+"]")))
+		buf.WriteString(",\n")
+	}
+	g.spewPath = spewPath
+	g.depth--
+	buf.WriteString(g.lineIndent())
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// renderMap returns a map as a composite literal.  Keys are sorted when the
+// SortKeys option is set.  Entries redacted by the Redact option are left as
+// comments.
+func (g *goState) renderMap(v reflect.Value, path string) string {
+	keys := v.MapKeys()
+	if len(keys) == 0 {
+		return v.Type().String() + "{}"
+	}
+	if g.cfg.SortKeys {
+		sortValues(keys, g.cfg)
+	}
+
+	keyTyped := v.Type().Key().Kind() == reflect.Interface
+	elemTyped := v.Type().Elem().Kind() == reflect.Interface
+	var buf strings.Builder
+	buf.WriteString(v.Type().String())
+	buf.WriteString("{\n")
+	g.depth++
+	spewPath := g.spewPath
+	for i, key := range keys {
+		k := g.render(key, keyTyped, "")
+		if uk, _ := unpack(key); redactString(g.cfg, uk) {
+			k = g.convert(uk, keyTyped, strconv.Quote(redactedKey(uk, i)))
+		}
+
+		// Map elements can be replaced as a whole, but can only be
+		// assigned into through pointers.
+		elem := v.MapIndex(key)
+		elemPath := ""
+		if kind := elem.Kind(); kind == reflect.Ptr || kind == reflect.Interface {
+			elemPath = child(path, "["+k+"]")
+		}
+		uk, _ := unpack(key)
+		g.spewPath = keyPath(g.cfg, spewPath, uk)
+		buf.WriteString(g.lineIndent())
+		if uk.Kind() == reflect.String && redactName(g.cfg, uk.String(), g.spewPath) {
+			ue, _ := unpack(elem)
+			buf.WriteString("// ")
+			buf.WriteString(k)
+			buf.WriteString(": ")
+			buf.Write(redactedLen(ue))
+			buf.WriteString("\n")
+			continue
+		}
+		buf.WriteString(k)
+		buf.WriteString(": ")
+		buf.WriteString(g.render(elem, elemTyped, elemPath))
+		buf.WriteString(",\n")
+	}
+	g.spewPath = spewPath
+	g.depth--
+	buf.WriteString(g.lineIndent())
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// renderStruct returns a struct as a composite literal.  Fields with zero
+// values are omitted, as are fields skipped by their spew struct tags.
+// Redacted fields, whether by their tags or the Redact option, are left as
+// comments, since there is no value to give them.
+func (g *goState) renderStruct(v reflect.Value, path string) string {
+	vt := v.Type()
+	var buf strings.Builder
+	buf.WriteString(vt.String())
+	buf.WriteString("{")
+	g.depth++
+	spewPath := g.spewPath
+	numFields := v.NumField()
+	wrote := false
+	for i := 0; i < numFields; i++ {
+		vtf := vt.Field(i)
+		// StructField has an IsExported() method, but only in 1.17+.
+		if g.cfg.DisableUnexported && vtf.PkgPath != "" {
+			continue
+		}
+		field, ok := fieldTag(v, i)
+		vf := v.Field(i)
+		if !ok || vf.IsZero() {
+			continue
+		}
+		if !wrote {
+			buf.WriteString("\n")
+			wrote = true
+		}
+		g.spewPath = spewPath + "." + vtf.Name
+		buf.WriteString(g.lineIndent())
+		if field.redact || redactName(g.cfg, vtf.Name, g.spewPath) {
+			marker := redactedBytes
+			if !field.redact {
+				uv, _ := unpack(vf)
+				marker = redactedLen(uv)
+			}
+			buf.WriteString("// ")
+			buf.WriteString(vtf.Name)
+			buf.WriteString(": ")
+			buf.Write(marker)
+			buf.WriteString("\n")
+			continue
+		}
+		buf.WriteString(vtf.Name)
+		buf.WriteString(": ")
+		typed := vtf.Type.Kind() == reflect.Interface
+		hex := g.hex
+		g.hex = hex || field.hex
+		buf.WriteString(g.render(vf, typed, child(path, "."+vtf.Name)))
+		g.hex = hex
+		buf.WriteString(",\n")
+	}
+	g.spewPath = spewPath
+	g.depth--
+	if wrote {
+		buf.WriteString(g.lineIndent())
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// sgo is a helper function to consolidate the logic from the various public
+// methods which take varying config states.
+func sgo(cfg *Config, a any) string {
+	g := goState{cfg: cfg, indent: cfg.Indent}
+	if g.indent == "" {
+		g.indent = "\t"
+	}
+	g.refs = make(map[uintptr]int)
+	g.names = make(map[uintptr]string)
+	g.done = make(map[uintptr]bool)
+
+	v := reflect.ValueOf(a)
+	countPointerRefs(cfg, v, g.refs)
+	if !v.IsValid() {
+		return "nil"
+	}
+
+	// Without any sharing the value is a single expression.
+	shared := false
+	for _, n := range g.refs {
+		if n > 1 {
+			shared = true
+			break
+		}
+	}
+	if !shared {
+		return g.render(v, true, "")
+	}
+
+	// Otherwise it is wrapped in a function literal which declares the
+	// helper variables, closes any cycles, and returns the value.
+	g.depth = 1
+	expr := g.render(v, false, "")
+	var buf strings.Builder
+	buf.WriteString("func() ")
+	buf.WriteString(v.Type().String())
+	buf.WriteString(" {\n")
+	for _, stmt := range append(g.decls, g.fixups...) {
+		buf.WriteString(g.indent)
+		buf.WriteString(stmt)
+		buf.WriteString("\n")
+	}
+	buf.WriteString(g.indent)
+	buf.WriteString("return ")
+	buf.WriteString(expr)
+	buf.WriteString("\n}()")
+	return buf.String()
+}
+
+// Fgo writes the passed argument to io.Writer w as Go source.  It formats
+// exactly the same as Sgo.
+func Fgo(w io.Writer, a any) {
+	w.Write([]byte(sgo(&Default, a)))
+}
+
+// Sgo returns the passed argument rendered as a Go expression which evaluates
+// to an equivalent value.  It is intended for capturing runtime values as
+// test fixtures:
+//
+//   - Structs, arrays, slices and maps are rendered as composite literals
+//     with package-qualified type names, and struct fields with zero values
+//     are omitted
+//   - Pointers are rendered as &T{...}
+//   - Pointers which are reached more than once, including through circular
+//     references, are bound to helper variables inside a function literal so
+//     that the sharing is preserved and the output still compiles
+//   - Functions, unsafe pointers and channel contents can't be reproduced and
+//     are rendered as nil with a comment, or as an empty channel
+//
+// Values of unexported types or with unexported fields only compile within
+// their own package.  The output may refer to the math package for special
+// float values.  The Indent, DisableUnexported and SortKeys options of the
+// global Default config are honored.
+func Sgo(a any) string {
+	return sgo(&Default, a)
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// goTest is used to describe a test to be performed against the Sgo method.
+type goTest struct {
+	line string // use line() to fill this
+	in   any
+	want string
+}
+
+// goNode is used to test sharing and circular references.
+type goNode struct {
+	Name  string
+	Next  *goNode
+	Items []*goNode
+	Attrs map[string]any
+	count int
+}
+
+// TestGoSyntax executes all of the tests described by goTests.
+func TestGoSyntax(t *testing.T) {
+	cfg := spew.Config{Indent: "\t", SortKeys: true}
+
+	i8 := int8(5)
+	shared := &goNode{Name: "shared"}
+	cyclic := &goNode{Name: "head", count: 1}
+	cyclic.Next = &goNode{Name: "tail", Next: cyclic}
+	self := &goNode{Name: "self"}
+	self.Attrs = map[string]any{"me": self}
+
+	tests := []goTest{
+		{line(), nil, "nil"},
+		{line(), 1, "1"},
+		{line(), int8(1), "int8(1)"},
+		{line(), uint(7), "uint(7)"},
+		{line(), 1.0, "1.0"},
+		{line(), float32(2.5), "float32(2.5)"},
+		{line(), math.Inf(-1), "math.Inf(-1)"},
+		{line(), complex(1, -2), "complex(1.0, -2.0)"},
+		{line(), "a\tb", `"a\tb"`},
+		{line(), true, "true"},
+		{line(), stringer("x"), `spew_test.stringer("x")`},
+		{line(), []byte("hi"), `[]uint8("hi")`},
+		{line(), []int(nil), "[]int(nil)"},
+		{line(), []int{}, "[]int{}"},
+		{line(), [2]int{1, 2}, "[2]int{\n\t1,\n\t2,\n}"},
+		{line(), map[string]int{"b": 2, "a": 1}, "map[string]int{\n\t\"a\": 1,\n\t\"b\": 2,\n}"},
+		{line(), []any{nil, uint8(1), "s"}, "[]interface {}{\n\tnil,\n\tuint8(1),\n\t\"s\",\n}"},
+		{line(), &i8, "&[]int8{5}[0]"},
+		{line(), (*int)(nil), "(*int)(nil)"},
+		{line(), goNode{}, "spew_test.goNode{}"},
+		{line(), &goNode{Name: "a", count: 2}, "&spew_test.goNode{\n\tName: \"a\",\n\tcount: 2,\n}"},
+		{line(), []*goNode{shared, shared},
+			"func() []*spew_test.goNode {\n" +
+				"\tp1 := &spew_test.goNode{\n\t\tName: \"shared\",\n\t}\n" +
+				"\treturn []*spew_test.goNode{\n\t\tp1,\n\t\tp1,\n\t}\n" +
+				"}()"},
+		{line(), cyclic,
+			"func() *spew_test.goNode {\n" +
+				"\tp1 := &spew_test.goNode{\n" +
+				"\t\tName: \"head\",\n" +
+				"\t\tNext: &spew_test.goNode{\n" +
+				"\t\t\tName: \"tail\",\n" +
+				"\t\t\tNext: nil,\n" +
+				"\t\t},\n" +
+				"\t\tcount: 1,\n" +
+				"\t}\n" +
+				"\tp1.Next.Next = p1\n" +
+				"\treturn p1\n" +
+				"}()"},
+		{line(), self,
+			"func() *spew_test.goNode {\n" +
+				"\tp1 := &spew_test.goNode{\n" +
+				"\t\tName: \"self\",\n" +
+				"\t\tAttrs: map[string]interface {}{\n" +
+				"\t\t\t\"me\": nil,\n" +
+				"\t\t},\n" +
+				"\t}\n" +
+				"\tp1.Attrs[\"me\"] = p1\n" +
+				"\treturn p1\n" +
+				"}()"},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		s := cfg.Sgo(test.in)
+		if s != test.want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, s, test.want)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	spew.Fgo(buf, goNode{Name: "a"})
+	if want := "spew_test.goNode{\n Name: \"a\",\n}"; buf.String() != want {
+		t.Errorf("spew.Fgo:\n got: %s\nwant: %s", buf.String(), want)
+	}
+}
@@ -0,0 +1,531 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"io"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+)
+
+// htmlHeader and htmlFooter surround the output of Fhtml so that it is a
+// standalone page which doesn't need any external assets.
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>spew</title>
+<style>
+body { font-family: monospace; font-size: 13px; }
+details { margin: 0; }
+.entry details { display: inline-block; vertical-align: top; }
+summary { cursor: pointer; }
+.entries { margin-left: 2ch; border-left: 1px dotted #ccc; padding-left: 1ch; }
+.type { color: #267f99; }
+.len { color: #888; }
+.addr { color: #a31515; }
+.field, .key { color: #001080; }
+.string { color: #a31515; }
+.value { color: #098658; }
+.method { color: #795e26; }
+.nil, .max, .redacted { color: #888; font-style: italic; }
+a.ref { color: #0000ee; font-style: italic; }
+pre.hex { margin: 0; }
+</style>
+</head>
+<body>
+<div class="spew">
+`
+
+const htmlFooter = `</div>
+</body>
+</html>
+`
+
+// Some constants in the form of bytes used by the HTML output.
+var (
+	htmlDetailsOpenBytes  = []byte("<details open><summary>")
+	htmlSummaryCloseBytes = []byte("</summary><div class=\"entries\">\n")
+	htmlDetailsCloseBytes = []byte("</div>")
+	htmlEntryOpenBytes    = []byte("<div class=\"entry\">")
+	htmlEntryCloseBytes   = []byte("</div>\n")
+	htmlDetailsEndBytes   = []byte("</details>")
+)
+
+// htmlState contains information about the state of an HTML output operation.
+type htmlState struct {
+	w              io.Writer
+	depth          int
+	ignoreNextType bool
+	cfg            *Config
+
+	// ids maps every pointer which has been output to the ordinal used in
+	// the id of its first occurrence, so later occurrences can link to it.
+	ids map[uintptr]int
+
+	// prefix and suffix are written around the next value.  Pointers use
+	// them so that their type and addresses end up in the summary of the
+	// collection they point to.
+	prefix []byte
+	suffix []byte
+
+	// hex is set while outputting a field with the hex spew struct tag.
+	hex bool
+
+	// path is the path of the value being output in the form Dump uses,
+	// such as .Items[3].Name, which the Redact option is matched against.
+	path string
+}
+
+// spanString returns text, escaped, in a span with the passed class.
+func spanString(class, text string) string {
+	return `<span class="` + class + `">` + html.EscapeString(text) + `</span>`
+}
+
+// span outputs text, escaped, in a span with the passed class.
+func (h *htmlState) span(class, text string) {
+	h.w.Write([]byte(spanString(class, text)))
+}
+
+// methodString returns the result of invoking the error or Stringer interface
+// on v, if there is one and it is not configured to continue on to the
+// underlying value.
+func (h *htmlState) methodString(v reflect.Value) (string, bool) {
+	var buf bytes.Buffer
+	if handled := handleMethods(h.cfg, &buf, v, Context{Config: h.cfg, Depth: h.depth, Inline: true}); handled {
+		return buf.String(), true
+	}
+	return "", false
+}
+
+// htmlPtr handles output of pointers by indirecting them as necessary.  The
+// first occurrence of each pointer is given an id, and every occurrence after
+// that, whether due to a circular reference or not, is output as a link to
+// it.
+func (h *htmlState) htmlPtr(v reflect.Value) {
+	// Figure out how many levels of indirection there are by dereferencing
+	// pointers and unpacking interfaces down the chain while detecting
+	// pointers which were already shown.
+	var pointerChain []uintptr
+	nilFound := false
+	shownID := 0
+	indirects := 0
+	ve := v
+	for ve.Kind() == reflect.Ptr {
+		if ve.IsNil() {
+			nilFound = true
+			break
+		}
+		addr := ve.Pointer()
+		if id, ok := h.ids[addr]; ok {
+			shownID = id
+			break
+		}
+		indirects++
+		pointerChain = append(pointerChain, addr)
+		h.ids[addr] = len(h.ids) + 1
+
+		ve = ve.Elem()
+		if ve.Kind() == reflect.Interface {
+			if ve.IsNil() {
+				nilFound = true
+				break
+			}
+			ve = ve.Elem()
+		}
+	}
+
+	// Display type information.
+	var prefix bytes.Buffer
+	typ := string(bytes.Repeat(asteriskBytes, indirects)) + ve.Type().String()
+	prefix.WriteString(spanString("type", "("+typ+")"))
+
+	// Display pointer information.  Anchors are output even when addresses
+	// are disabled, so that links to them still work.
+	if h.cfg.DisablePointerAddresses {
+		for _, addr := range pointerChain {
+			fmt.Fprintf(&prefix, `<a id="ptr-%d"></a>`, h.ids[addr])
+		}
+	} else if len(pointerChain) > 0 {
+		prefix.Write(openParenBytes)
+		for i, addr := range pointerChain {
+			if i > 0 {
+				prefix.Write(pointerChainBytes)
+			}
+			var buf bytes.Buffer
+			printHexPtr(&buf, addr)
+			fmt.Fprintf(&prefix, `<span class="addr" id="ptr-%d">%s</span>`, h.ids[addr], buf.String())
+		}
+		prefix.Write(closeParenBytes)
+	}
+	prefix.Write(openParenBytes)
+
+	// Display dereferenced value.
+	switch {
+	case nilFound:
+		h.w.Write(prefix.Bytes())
+		h.span("nil", string(nilAngleBytes))
+		h.w.Write(closeParenBytes)
+
+	case shownID != 0:
+		h.w.Write(prefix.Bytes())
+		fmt.Fprintf(h.w, `<a class="ref" href="#ptr-%d">%s</a>`, shownID,
+			html.EscapeString(string(circularBytes)))
+		h.w.Write(closeParenBytes)
+
+	default:
+		h.prefix = prefix.Bytes()
+		h.suffix = closeParenBytes
+		h.ignoreNextType = true
+		h.html(ve)
+	}
+}
+
+// isCollection returns whether v is output as a collapsible node.  Empty
+// collections and byte arrays and slices are output inline.
+func isCollection(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return false
+		}
+		fallthrough
+
+	case reflect.Array:
+		if v.Kind() != reflect.Map && v.Type().Elem().Kind() == reflect.Uint8 {
+			return false
+		}
+		return v.Len() > 0
+
+	case reflect.Struct:
+		return v.NumField() > 0
+	}
+	return false
+}
+
+// entry outputs a single entry of a collection.  The key, which may be empty,
+// has already been rendered.
+func (h *htmlState) entry(key string, v reflect.Value) {
+	h.w.Write(htmlEntryOpenBytes)
+	if key != "" {
+		h.w.Write([]byte(key))
+		h.w.Write(colonSpaceBytes)
+	}
+	h.html(v)
+	h.w.Write(htmlEntryCloseBytes)
+}
+
+// redactedEntry outputs a single entry of a collection whose value is not
+// shown, with the passed marker in its place.
+func (h *htmlState) redactedEntry(key string, marker []byte) {
+	h.w.Write(htmlEntryOpenBytes)
+	h.w.Write([]byte(key))
+	h.w.Write(colonSpaceBytes)
+	h.span("redacted", string(marker))
+	h.w.Write(htmlEntryCloseBytes)
+}
+
+// htmlEntries outputs the entries of an array, slice, map or struct.
+func (h *htmlState) htmlEntries(v reflect.Value) {
+	path := h.path
+	switch v.Kind() {
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			h.path = indexPath(path, i)
+			h.entry("", h.unpackValue(v.Index(i)))
+		}
+
+	case reflect.Map:
+		keys := v.MapKeys()
+		if h.cfg.SortKeys {
+			sortValues(keys, h.cfg)
+		}
+		for i, key := range keys {
+			// Keys are rendered inline the same way the Formatter would
+			// render them.
+			k := ""
+			uk := h.unpackValue(key)
+			switch {
+			case redactString(h.cfg, uk):
+				k = redactedKey(uk, i)
+			case key.Kind() == reflect.String:
+				k = strconv.Quote(key.String())
+			default:
+				k = sprintValue(h.cfg, uk, "")
+			}
+			ev := h.unpackValue(v.MapIndex(key))
+			h.path = keyPath(h.cfg, path, uk)
+			if key.Kind() == reflect.String && redactName(h.cfg, key.String(), h.path) {
+				h.redactedEntry(spanString("key", k), redactedLen(ev))
+				continue
+			}
+			h.entry(spanString("key", k), ev)
+		}
+
+	case reflect.Struct:
+		vt := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			vtf := vt.Field(i)
+			// StructField has an IsExported() method, but only in 1.17+.
+			if h.cfg.DisableUnexported && vtf.PkgPath != "" {
+				continue
+			}
+			field, ok := fieldTag(v, i)
+			if !ok {
+				continue
+			}
+			fv := h.unpackValue(v.Field(i))
+			h.path = path + "." + vtf.Name
+			switch {
+			case field.redact:
+				h.redactedEntry(spanString("field", vtf.Name), redactedBytes)
+			case redactName(h.cfg, vtf.Name, h.path):
+				h.redactedEntry(spanString("field", vtf.Name), redactedLen(fv))
+			default:
+				hex := h.hex
+				h.hex = hex || field.hex
+				h.entry(spanString("field", vtf.Name), fv)
+				h.hex = hex
+			}
+		}
+	}
+	h.path = path
+}
+
+// unpackValue returns values inside of non-nil interfaces when possible.
+func (h *htmlState) unpackValue(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// html is the main workhorse for HTML output.  It walks values the same way
+// dump does, but outputs collections as collapsible details elements.
+func (h *htmlState) html(v reflect.Value) {
+	// Handle invalid reflect values immediately.
+	kind := v.Kind()
+	if kind == reflect.Invalid {
+		h.span("nil", string(invalidAngleBytes))
+		return
+	}
+
+	// Handle pointers specially.
+	if kind == reflect.Ptr {
+		h.htmlPtr(v)
+		return
+	}
+
+	// The prefix and suffix only apply to this value, not to any values
+	// nested inside of it.
+	prefix, suffix := h.prefix, h.suffix
+	h.prefix, h.suffix = nil, nil
+
+	// Call Stringer/error interfaces if they exist and the handle methods
+	// flag is enabled.
+	method, handled := "", false
+	if !h.cfg.DisableMethods && kind != reflect.Interface {
+		method, handled = h.methodString(v)
+	}
+
+	collection := !handled && isCollection(v)
+	if collection {
+		h.w.Write(htmlDetailsOpenBytes)
+	}
+	h.w.Write(prefix)
+
+	// Display type information unless already handled elsewhere.
+	if !h.ignoreNextType && !h.cfg.DisableTypes {
+		h.span("type", "("+v.Type().String()+")")
+		h.w.Write(spaceBytes)
+	}
+	h.ignoreNextType = false
+
+	// Display length and capacity the same way dump does.
+	if !h.cfg.DisableLengths {
+		valueLen, valueCap := 0, 0
+		switch kind {
+		case reflect.Array, reflect.Slice, reflect.Chan:
+			valueLen, valueCap = v.Len(), v.Cap()
+		case reflect.Map, reflect.String:
+			valueLen = v.Len()
+		}
+		var buf bytes.Buffer
+		if valueLen != 0 {
+			buf.Write(lenEqualsBytes)
+			printInt(&buf, int64(valueLen), 10)
+		}
+		if !h.cfg.DisableCapacities && valueCap != 0 {
+			if valueLen != 0 {
+				buf.Write(spaceBytes)
+			}
+			buf.Write(capEqualsBytes)
+			printInt(&buf, int64(valueCap), 10)
+		}
+		if buf.Len() > 0 {
+			h.span("len", "("+buf.String()+")")
+			h.w.Write(spaceBytes)
+		}
+	}
+
+	if handled {
+		h.span("method", method)
+		h.w.Write(suffix)
+		return
+	}
+
+	if collection {
+		h.w.Write(openBraceBytes)
+		h.w.Write(htmlSummaryCloseBytes)
+		h.depth++
+		if h.cfg.MaxDepth != 0 && h.depth > h.cfg.MaxDepth {
+			h.w.Write(htmlEntryOpenBytes)
+			h.span("max", string(maxNewlineBytes[:len(maxNewlineBytes)-1]))
+			h.w.Write(htmlEntryCloseBytes)
+		} else {
+			h.htmlEntries(v)
+		}
+		h.depth--
+		h.w.Write(htmlDetailsCloseBytes)
+		h.w.Write(closeBraceBytes)
+		h.w.Write(suffix)
+		h.w.Write(htmlDetailsEndBytes)
+		return
+	}
+
+	var buf bytes.Buffer
+	switch kind {
+	case reflect.Bool:
+		printBool(&buf, v.Bool())
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		buf.WriteString(formatInt(v, h.hex))
+
+	case reflect.Float32:
+		printFloat(&buf, v.Float(), 32)
+
+	case reflect.Float64:
+		printFloat(&buf, v.Float(), 64)
+
+	case reflect.Complex64:
+		printComplex(&buf, v.Complex(), 32)
+
+	case reflect.Complex128:
+		printComplex(&buf, v.Complex(), 64)
+
+	case reflect.String:
+		if redactString(h.cfg, v) {
+			h.span("redacted", string(redactedLen(v)))
+			break
+		}
+		h.span("string", strconv.Quote(v.String()))
+
+	case reflect.Interface:
+		// The only time we should get here is for nil interfaces due to
+		// unpackValue calls.
+		h.span("nil", string(nilAngleBytes))
+
+	case reflect.Slice, reflect.Array, reflect.Map:
+		switch {
+		case kind != reflect.Array && v.IsNil():
+			h.span("nil", string(nilAngleBytes))
+
+		case v.Len() == 0 && kind == reflect.Map:
+			h.w.Write(emptyBracesBytes)
+
+		case v.Len() == 0:
+			h.w.Write(emptyListBytes)
+
+		// Byte arrays and slices are output in hexdump -C fashion.
+		default:
+			data := make([]byte, v.Len())
+			for i := range data {
+				data[i] = uint8(v.Index(i).Uint())
+			}
+			h.w.Write([]byte(`<pre class="hex">`))
+			h.w.Write([]byte(html.EscapeString(hex.Dump(data))))
+			h.w.Write([]byte(`</pre>`))
+		}
+
+	case reflect.Struct:
+		h.w.Write(emptyBracesBytes)
+
+	case reflect.Uintptr:
+		printHexPtr(&buf, uintptr(v.Uint()))
+
+	case reflect.UnsafePointer, reflect.Chan:
+		printHexPtr(&buf, v.Pointer())
+
+	case reflect.Func:
+		if fn := runtime.FuncForPC(v.Pointer()); h.cfg.FuncSymbols && fn != nil {
+			file, line := fn.FileLine(v.Pointer())
+			fmt.Fprintf(&buf, "%s[%s:%d]", filepath.Base(fn.Name()), filepath.Base(file), line)
+		} else {
+			printHexPtr(&buf, v.Pointer())
+		}
+
+	// There were not any other types at the time this code was written, but
+	// fall back to letting the Formatter handle it in case any new types are
+	// added.
+	default:
+		buf.WriteString(sprintValue(h.cfg, v, ""))
+	}
+	if buf.Len() > 0 {
+		h.span("value", buf.String())
+	}
+	h.w.Write(suffix)
+}
+
+// fhtml is a helper function to consolidate the logic from the various public
+// methods which take varying writers and config states.
+func fhtml(cfg *Config, w io.Writer, a any) {
+	h := htmlState{w: w, cfg: cfg}
+	h.ids = make(map[uintptr]int)
+	io.WriteString(w, htmlHeader)
+	if a == nil {
+		// A nil interface has no type of its own, so output it the same way
+		// Dump does.
+		h.span("type", string(interfaceBytes))
+		h.w.Write(spaceBytes)
+		h.span("nil", string(nilAngleBytes))
+	} else {
+		h.html(reflect.ValueOf(a))
+	}
+	h.w.Write(newlineBytes)
+	io.WriteString(w, htmlFooter)
+}
+
+// Fhtml formats and displays the passed argument to io.Writer w as a
+// standalone HTML page, which doesn't need any external assets.  Values are
+// walked the same way Dump walks them.  Structs, maps, arrays and slices are
+// output as collapsible details elements, and types, lengths and pointer
+// addresses are output as styled spans.  Every occurrence of a pointer after
+// the first, whether due to a circular reference or not, is output as a link
+// to the first one.
+//
+// The configuration options are controlled by an exported package global,
+// spew.Default.  See Config for options documentation.
+func Fhtml(w io.Writer, a any) {
+	fhtml(&Default, w, a)
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// htmlTest is used to describe a test to be performed against the Fhtml
+// method.  The output must contain every one of wants.
+type htmlTest struct {
+	line  string // use line() to fill this
+	cfg   *spew.Config
+	in    any
+	wants []string
+}
+
+// htmlNode is used to test structs and circular references.
+type htmlNode struct {
+	Name string
+	Next *htmlNode
+}
+
+// TestHTML executes all of the tests described by htmlTests.
+func TestHTML(t *testing.T) {
+	cfgDefault := &spew.Config{Indent: " ", SortKeys: true}
+	cfgClean := &spew.Config{DisableTypes: true, DisableLengths: true,
+		DisablePointerAddresses: true}
+	cfgMaxDepth := &spew.Config{MaxDepth: 1}
+
+	circ := &htmlNode{Name: "circ"}
+	circ.Next = circ
+
+	tests := []htmlTest{
+		{line(), cfgDefault, 5, []string{
+			`<span class="type">(int)</span> <span class="value">5</span>`,
+		}},
+		{line(), cfgDefault, "<b>", []string{
+			`<span class="len">(len=3)</span> <span class="string">&#34;&lt;b&gt;&#34;</span>`,
+		}},
+		{line(), cfgClean, nil, []string{
+			`<span class="type">(interface {})</span> <span class="nil">&lt;nil&gt;</span>`,
+		}},
+		{line(), cfgDefault, []any{nil}, []string{
+			`<span class="type">(interface {})</span> <span class="nil">&lt;nil&gt;</span>`,
+		}},
+		{line(), cfgClean, []int{1, 2}, []string{
+			`<details open><summary>{</summary><div class="entries">`,
+			`<div class="entry"><span class="value">1</span></div>`,
+			`</div>}</details>`,
+		}},
+		{line(), cfgClean, map[string]int{"a": 1}, []string{
+			`<div class="entry"><span class="key">&#34;a&#34;</span>: <span class="value">1</span></div>`,
+		}},
+		{line(), cfgClean, []int{}, []string{`<div class="spew">` + "\n[]\n"}},
+		{line(), cfgClean, []byte("hi"), []string{
+			`<pre class="hex">00000000  68 69`,
+		}},
+		{line(), cfgClean, stringer("x"), []string{
+			`<span class="method">stringer x</span>`,
+		}},
+		{line(), cfgDefault, circ, []string{
+			`<summary><span class="type">(*spew_test.htmlNode)</span>(<span class="addr" id="ptr-1">0x`,
+			`<span class="field">Next</span>: <span class="type">(*spew_test.htmlNode)</span>(<a class="ref" href="#ptr-1">&lt;already shown&gt;</a>)`,
+		}},
+		{line(), cfgClean, circ, []string{
+			`<summary><span class="type">(*spew_test.htmlNode)</span><a id="ptr-1"></a>({</summary>`,
+			`<a class="ref" href="#ptr-1">`,
+			`})</details>`,
+		}},
+		{line(), cfgClean, []*htmlNode{circ, circ}, []string{
+			`<div class="entry"><span class="type">(*spew_test.htmlNode)</span>(<a class="ref" href="#ptr-1">&lt;already shown&gt;</a>)</div>`,
+		}},
+		{line(), cfgMaxDepth, [][]int{{1}}, []string{
+			`<span class="max">&lt;max depth reached&gt;</span>`,
+		}},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		var buf bytes.Buffer
+		test.cfg.Fhtml(&buf, test.in)
+		s := buf.String()
+		if !strings.HasPrefix(s, "<!DOCTYPE html>") || !strings.HasSuffix(s, "</html>\n") {
+			t.Errorf("testcase on line %s: not a complete page:\n%s", test.line, s)
+			continue
+		}
+		if strings.Count(s, "<details") != strings.Count(s, "</details>") {
+			t.Errorf("testcase on line %s: unbalanced details:\n%s", test.line, s)
+		}
+		for _, want := range test.wants {
+			if !strings.Contains(s, want) {
+				t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, s, want)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	spew.Fhtml(&buf, 1)
+	if !strings.Contains(buf.String(), `<span class="value">1</span>`) {
+		t.Errorf("spew.Fhtml:\n got: %s", buf.String())
+	}
+}
@@ -55,7 +55,7 @@ func TestInvalidReflectValue(t *testing.T) {
 	// Dump invalid reflect value.
 	v := new(reflect.Value)
 	buf := new(bytes.Buffer)
-	d := dumpState{w: buf, cfg: &Default}
+	d := newDumpState(&Default, buf)
 	d.dump(*v)
 	s := buf.String()
 	want := "<invalid>"
@@ -66,7 +66,7 @@ func TestInvalidReflectValue(t *testing.T) {
 
 	// Formatter invalid reflect value.
 	buf2 := new(dummyFmtState)
-	f := formatState{value: *v, cfg: &Default, fs: buf2}
+	f := newFormatState(&Default, buf2)
 	f.format(*v)
 	s = buf2.String()
 	want = "<invalid>"
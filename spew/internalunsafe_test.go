@@ -55,7 +55,7 @@ func TestAddedReflectValue(t *testing.T) {
 	v := reflect.ValueOf(int8(5))
 	changeKind(&v, false)
 	buf := new(bytes.Buffer)
-	d := dumpState{w: buf, cfg: &Default}
+	d := newDumpState(&Default, buf)
 	d.dump(v)
 	s := buf.String()
 	want := "(int8) 5"
@@ -78,7 +78,7 @@ func TestAddedReflectValue(t *testing.T) {
 	// Formatter using a reflect.Value that is exported.
 	changeKind(&v, false)
 	buf2 := new(dummyFmtState)
-	f := formatState{value: v, cfg: &Default, fs: buf2}
+	f := newFormatState(&Default, buf2)
 	f.format(v)
 	s = buf2.String()
 	want = "5"
@@ -90,7 +90,7 @@ func TestAddedReflectValue(t *testing.T) {
 	// Formatter using a reflect.Value that is not exported.
 	changeKind(&v, true)
 	buf2.Reset()
-	f = formatState{value: v, cfg: &Default, fs: buf2}
+	f = newFormatState(&Default, buf2)
 	f.format(v)
 	s = buf2.String()
 	want = "<int8 Value>"
@@ -0,0 +1,588 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"math"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Some constants in the form of bytes used by the JSON output.
+var (
+	jsonNullBytes       = []byte("null")
+	jsonOpenArrayBytes  = []byte("[")
+	jsonCloseArrayBytes = []byte("]")
+	jsonColonBytes      = []byte(":")
+)
+
+// jsonMaxDepth is output in place of values nested deeper than MaxDepth.
+const jsonMaxDepth = "<max depth reached>"
+
+// jsonState contains information about the state of a JSON output
+// operation.
+type jsonState struct {
+	w        io.Writer
+	depth    int
+	pointers map[uintptr]int
+	paths    map[uintptr]string
+	cfg      *Config
+
+	// mcfg is used to invoke error and Stringer methods, so that their
+	// output is captured as-is regardless of ContinueOnMethod and
+	// QuoteStrings.
+	mcfg Config
+
+	// level and members track the nesting of JSON objects and arrays in
+	// the output, and whether the innermost one has any members yet.
+	level   int
+	members []bool
+
+	// spewPath is the path of the value being output in the form Dump
+	// uses, such as .Items[3].Name, which the Redact option is matched
+	// against.
+	spewPath string
+}
+
+// unpackValue returns values inside of non-nil interfaces when possible.
+func (j *jsonState) unpackValue(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// newline starts a new line at the current nesting level when the output is
+// indented.
+func (j *jsonState) newline() {
+	if j.cfg.Indent == "" {
+		return
+	}
+	j.w.Write(newlineBytes)
+	j.w.Write(bytes.Repeat([]byte(j.cfg.Indent), j.level))
+}
+
+// open starts a JSON object or array.
+func (j *jsonState) open(b []byte) {
+	j.w.Write(b)
+	j.level++
+	j.members = append(j.members, false)
+}
+
+// close ends the innermost JSON object or array.
+func (j *jsonState) close(b []byte) {
+	j.level--
+	hadMembers := j.members[len(j.members)-1]
+	j.members = j.members[:len(j.members)-1]
+	if hadMembers {
+		j.newline()
+	}
+	j.w.Write(b)
+}
+
+// element starts the next member of the innermost JSON array.
+func (j *jsonState) element() {
+	if j.members[len(j.members)-1] {
+		j.w.Write(commaBytes)
+	}
+	j.members[len(j.members)-1] = true
+	j.newline()
+}
+
+// member starts the next member of the innermost JSON object.
+func (j *jsonState) member(name string) {
+	j.element()
+	writeJSONString(j.w, name)
+	if j.cfg.Indent == "" {
+		j.w.Write(jsonColonBytes)
+	} else {
+		j.w.Write(colonSpaceBytes)
+	}
+}
+
+// methodString returns the result of invoking the error or Stringer interface
+// on v, if there is one.
+func (j *jsonState) methodString(v reflect.Value) (string, bool) {
+	var buf bytes.Buffer
+	if handled := handleMethods(&j.mcfg, &buf, v, Context{Config: j.cfg, Depth: j.depth, Inline: true}); handled {
+		return buf.String(), true
+	}
+	return "", false
+}
+
+// jsonPointerToken escapes s for use as a single JSON pointer token as
+// described in RFC 6901.
+func jsonPointerToken(s string) string {
+	s = strings.ReplaceAll(s, "~", "~0")
+	return strings.ReplaceAll(s, "/", "~1")
+}
+
+// jsonPtr handles output of pointers by indirecting them as necessary.
+// Circular references are output as {"$ref": "#/path"} objects which refer
+// to the first occurrence of the pointer.
+func (j *jsonState) jsonPtr(v reflect.Value, path string) {
+	// Remove pointers at or below the current depth from map used to detect
+	// circular refs.
+	for k, depth := range j.pointers {
+		if depth >= j.depth {
+			delete(j.pointers, k)
+		}
+	}
+
+	// Keep list of all dereferenced pointers to show later.
+	pointerChain := make([]uintptr, 0)
+
+	// Figure out how many levels of indirection there are by dereferencing
+	// pointers and unpacking interfaces down the chain while detecting circular
+	// references.
+	nilFound := false
+	cycleFound := false
+	indirects := 0
+	ve := v
+	for ve.Kind() == reflect.Ptr {
+		if ve.IsNil() {
+			nilFound = true
+			break
+		}
+		indirects++
+		addr := ve.Pointer()
+		pointerChain = append(pointerChain, addr)
+		if pd, ok := j.pointers[addr]; ok && pd < j.depth {
+			cycleFound = true
+			break
+		}
+		j.pointers[addr] = j.depth
+		if _, ok := j.paths[addr]; !ok {
+			j.paths[addr] = path
+		}
+
+		ve = ve.Elem()
+		if ve.Kind() == reflect.Interface {
+			if ve.IsNil() {
+				nilFound = true
+				break
+			}
+			ve = ve.Elem()
+		}
+	}
+
+	if cycleFound {
+		j.open(openBraceBytes)
+		j.member("$ref")
+		writeJSONString(j.w, "#"+j.paths[pointerChain[len(pointerChain)-1]])
+		j.close(closeBraceBytes)
+		return
+	}
+
+	typ := strings.Repeat("*", indirects) + ve.Type().String()
+	if nilFound {
+		ve = reflect.Value{}
+	}
+	j.node(ve, typ, pointerChain, path)
+}
+
+// json handles output of any value, which may be a pointer.
+func (j *jsonState) json(v reflect.Value, path string) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		j.w.Write(jsonNullBytes)
+
+	case reflect.Ptr:
+		j.jsonPtr(v, path)
+
+	default:
+		j.node(v, v.Type().String(), nil, path)
+	}
+}
+
+// node outputs a single non-pointer value along with its metadata.  Structs
+// are output as objects with the metadata members first, followed by the
+// fields.  Other values are wrapped in an object with the metadata members
+// and a "$value" member when there is any metadata to show.  The value may be
+// invalid if it is the target of a nil pointer.
+func (j *jsonState) node(v reflect.Value, typ string, addrs []uintptr, path string) {
+	kind := v.Kind()
+
+	// Call Stringer/error interfaces if they exist and the handle methods flag
+	// is enabled.
+	str, handled := "", false
+	if !j.cfg.DisableMethods && kind != reflect.Invalid && kind != reflect.Interface {
+		str, handled = j.methodString(v)
+	}
+	maxed := false
+	switch kind {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Struct:
+		maxed = j.cfg.MaxDepth != 0 && j.depth+1 > j.cfg.MaxDepth
+	}
+
+	// Figure out which metadata to show.
+	showType := !j.cfg.DisableTypes
+	showAddrs := !j.cfg.DisablePointerAddresses && len(addrs) > 0
+	showLen, showCap := false, false
+	if !j.cfg.DisableLengths {
+		switch kind {
+		case reflect.Array, reflect.Slice, reflect.Chan:
+			showLen, showCap = true, !j.cfg.DisableCapacities
+		case reflect.Map, reflect.String:
+			showLen = true
+		}
+		if (kind == reflect.Slice || kind == reflect.Map) && v.IsNil() {
+			showLen, showCap = false, false
+		}
+	}
+	showString := handled && j.cfg.ContinueOnMethod
+	if handled && !j.cfg.ContinueOnMethod {
+		showLen, showCap = false, false
+	}
+	if !showType && !showAddrs && !showLen && !showCap && !showString {
+		j.payload(v, str, handled && !j.cfg.ContinueOnMethod, maxed, path)
+		return
+	}
+
+	j.open(openBraceBytes)
+	if showType {
+		j.member("$type")
+		writeJSONString(j.w, typ)
+	}
+	if showAddrs {
+		j.member("$addrs")
+		j.open(jsonOpenArrayBytes)
+		for _, addr := range addrs {
+			j.element()
+			var buf bytes.Buffer
+			printHexPtr(&buf, addr)
+			writeJSONString(j.w, buf.String())
+		}
+		j.close(jsonCloseArrayBytes)
+	}
+	if showLen {
+		j.member("len")
+		printInt(j.w, int64(v.Len()), 10)
+	}
+	if showCap {
+		j.member("cap")
+		printInt(j.w, int64(v.Cap()), 10)
+	}
+	if showString {
+		j.member("$string")
+		writeJSONString(j.w, str)
+	}
+	if kind == reflect.Struct && !maxed && !(handled && !j.cfg.ContinueOnMethod) {
+		j.depth++
+		j.fields(v, path)
+		j.depth--
+	} else {
+		j.member("$value")
+		j.payload(v, str, handled && !j.cfg.ContinueOnMethod, maxed, path+"/$value")
+	}
+	j.close(closeBraceBytes)
+}
+
+// fields outputs the fields of a struct as members of the current object.
+// Fields are skipped and redacted according to their spew struct tags and the
+// Redact option.
+func (j *jsonState) fields(v reflect.Value, path string) {
+	spewPath := j.spewPath
+	vt := v.Type()
+	numFields := v.NumField()
+	for i := 0; i < numFields; i++ {
+		vtf := vt.Field(i)
+		// StructField has an IsExported() method, but only in 1.17+.
+		if j.cfg.DisableUnexported && vtf.PkgPath != "" {
+			continue
+		}
+		field, ok := fieldTag(v, i)
+		if !ok {
+			continue
+		}
+		j.member(vtf.Name)
+		fv := j.unpackValue(v.Field(i))
+		j.spewPath = spewPath + "." + vtf.Name
+		switch {
+		case field.redact:
+			writeJSONString(j.w, string(redactedBytes))
+		case redactName(j.cfg, vtf.Name, j.spewPath):
+			writeJSONString(j.w, string(redactedLen(fv)))
+		default:
+			j.json(fv, path+"/"+jsonPointerToken(vtf.Name))
+		}
+	}
+	j.spewPath = spewPath
+}
+
+// payload outputs the value itself, without any metadata.
+func (j *jsonState) payload(v reflect.Value, str string, handled, maxed bool, path string) {
+	if handled {
+		writeJSONString(j.w, str)
+		return
+	}
+	if maxed {
+		writeJSONString(j.w, jsonMaxDepth)
+		return
+	}
+
+	switch kind := v.Kind(); kind {
+	case reflect.Invalid:
+		j.w.Write(jsonNullBytes)
+
+	case reflect.Bool:
+		printBool(j.w, v.Bool())
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		printInt(j.w, v.Int(), 10)
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		printUint(j.w, v.Uint(), 10)
+
+	case reflect.Float32, reflect.Float64:
+		bitSize := 64
+		if kind == reflect.Float32 {
+			bitSize = 32
+		}
+		// JSON has no representation of these, so they are output as
+		// strings.
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			writeJSONString(j.w, strconv.FormatFloat(f, 'g', -1, bitSize))
+			break
+		}
+		printFloat(j.w, f, bitSize)
+
+	case reflect.Complex64, reflect.Complex128:
+		bitSize := 64
+		if kind == reflect.Complex64 {
+			bitSize = 32
+		}
+		var buf bytes.Buffer
+		printComplex(&buf, v.Complex(), bitSize)
+		writeJSONString(j.w, buf.String())
+
+	case reflect.String:
+		if redactString(j.cfg, v) {
+			writeJSONString(j.w, string(redactedLen(v)))
+			break
+		}
+		writeJSONString(j.w, v.String())
+
+	case reflect.Interface:
+		// The only time we should get here is for nil interfaces due to
+		// unpackValue calls.
+		j.w.Write(jsonNullBytes)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			j.w.Write(jsonNullBytes)
+			break
+		}
+		// Byte slices are output as base64 strings, the same as the
+		// encoding/json package does.
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			buf := make([]byte, v.Len())
+			for i := range buf {
+				buf[i] = uint8(v.Index(i).Uint())
+			}
+			writeJSONString(j.w, base64.StdEncoding.EncodeToString(buf))
+			break
+		}
+		fallthrough
+
+	case reflect.Array:
+		j.depth++
+		j.open(jsonOpenArrayBytes)
+		numEntries := v.Len()
+		spewPath := j.spewPath
+		for i := 0; i < numEntries; i++ {
+			j.element()
+			j.spewPath = indexPath(spewPath, i)
+			j.json(j.unpackValue(v.Index(i)), path+"/"+strconv.Itoa(i))
+		}
+		j.spewPath = spewPath
+		j.close(jsonCloseArrayBytes)
+		j.depth--
+
+	case reflect.Map:
+		if v.IsNil() {
+			j.w.Write(jsonNullBytes)
+			break
+		}
+		j.depth++
+		j.open(openBraceBytes)
+		keys := v.MapKeys()
+		if j.cfg.SortKeys {
+			sortValues(keys, j.cfg)
+		}
+		spewPath := j.spewPath
+		for i, key := range keys {
+			// JSON only allows string keys, so any other keys are
+			// formatted inline the same way the Formatter would.
+			name := ""
+			uk := j.unpackValue(key)
+			switch {
+			case redactString(j.cfg, uk):
+				name = redactedKey(uk, i)
+			case key.Kind() == reflect.String:
+				name = key.String()
+			default:
+				name = sprintValue(j.cfg, uk, "")
+			}
+			j.member(name)
+			ev := j.unpackValue(v.MapIndex(key))
+			j.spewPath = keyPath(j.cfg, spewPath, uk)
+			if key.Kind() == reflect.String && redactName(j.cfg, key.String(), j.spewPath) {
+				writeJSONString(j.w, string(redactedLen(ev)))
+				continue
+			}
+			j.json(ev, path+"/"+jsonPointerToken(name))
+		}
+		j.spewPath = spewPath
+		j.close(closeBraceBytes)
+		j.depth--
+
+	case reflect.Struct:
+		j.depth++
+		j.open(openBraceBytes)
+		j.fields(v, path)
+		j.close(closeBraceBytes)
+		j.depth--
+
+	case reflect.Uintptr:
+		var buf bytes.Buffer
+		printHexPtr(&buf, uintptr(v.Uint()))
+		writeJSONString(j.w, buf.String())
+
+	case reflect.UnsafePointer, reflect.Chan:
+		if v.Pointer() == 0 {
+			j.w.Write(jsonNullBytes)
+			break
+		}
+		var buf bytes.Buffer
+		printHexPtr(&buf, v.Pointer())
+		writeJSONString(j.w, buf.String())
+
+	case reflect.Func:
+		if v.IsNil() {
+			j.w.Write(jsonNullBytes)
+			break
+		}
+		var buf bytes.Buffer
+		if fn := runtime.FuncForPC(v.Pointer()); j.cfg.FuncSymbols && fn != nil {
+			file, line := fn.FileLine(v.Pointer())
+			buf.WriteString(filepath.Base(fn.Name()))
+			buf.WriteString("[")
+			buf.WriteString(filepath.Base(file))
+			buf.WriteString(":")
+			printInt(&buf, int64(line), 10)
+			buf.WriteString("]")
+		} else {
+			printHexPtr(&buf, v.Pointer())
+		}
+		writeJSONString(j.w, buf.String())
+
+	// There were not any other types at the time this code was written, but
+	// fall back to letting the Formatter handle it in case any new types are
+	// added.
+	default:
+		writeJSONString(j.w, sprintValue(j.cfg, v, ""))
+	}
+}
+
+// writeJSONString outputs s as a JSON string to Writer w.  Invalid UTF-8 is
+// replaced with the Unicode replacement character.
+func writeJSONString(w io.Writer, s string) {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		switch {
+		case r == '"' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r < 0x20:
+			buf.WriteString(`\u00`)
+			buf.WriteByte(hexDigits[r>>4])
+			buf.WriteByte(hexDigits[r&0xf])
+		default:
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+	w.Write(buf.Bytes())
+}
+
+// fjson is a helper function to consolidate the logic from the various public
+// methods which take varying writers and config states.
+func fjson(cfg *Config, w io.Writer, a any) {
+	j := jsonState{w: w, cfg: cfg, mcfg: *cfg}
+	j.mcfg.ContinueOnMethod = false
+	j.mcfg.QuoteStrings = false
+	j.pointers = make(map[uintptr]int)
+	j.paths = make(map[uintptr]string)
+	if a == nil {
+		j.node(reflect.Value{}, "interface {}", nil, "")
+	} else {
+		j.json(reflect.ValueOf(a), "")
+	}
+	j.w.Write(newlineBytes)
+}
+
+// Fjson formats and displays the passed argument to io.Writer w as JSON.  It
+// formats exactly the same as Sjson.
+func Fjson(w io.Writer, a any) {
+	fjson(&Default, w, a)
+}
+
+// Sjson returns a string with the passed argument formatted as JSON.  Values
+// are walked the same way Dump walks them, and each one is output as a JSON
+// value with optional metadata:
+//
+//   - "$type" holds the type, unless DisableTypes is set
+//   - "$addrs" holds the chain of pointer addresses used to indirect to the
+//     value, unless DisablePointerAddresses is set
+//   - "len" and "cap" hold the length and capacity, subject to
+//     DisableLengths and DisableCapacities
+//   - "$string" holds the result of invoking an error or Stringer interface
+//     when ContinueOnMethod is set; otherwise the result replaces the value
+//
+// Structs are output as objects with the metadata followed by the fields.
+// Any other value with metadata is wrapped in an object with the metadata and
+// a "$value" member.  Map keys which are not strings are formatted inline the
+// same way the Formatter would, and byte slices are base64 encoded like the
+// encoding/json package does.
+//
+// Circular references are output as {"$ref": "#/path"}, where the path is a
+// JSON pointer (RFC 6901) to the first occurrence of the pointer.
+//
+// The output is indented with the Indent option, or compact if it is empty.
+func Sjson(a any) string {
+	var buf bytes.Buffer
+	fjson(&Default, &buf, a)
+	return buf.String()
+}
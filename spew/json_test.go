@@ -0,0 +1,131 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// jsonTest is used to describe a test to be performed against the Sjson
+// method.
+type jsonTest struct {
+	line string // use line() to fill this
+	cfg  *spew.Config
+	in   any
+	want string
+}
+
+// jsonNode is used to test structs, nesting and circular references.
+type jsonNode struct {
+	Name  string
+	Items []*jsonNode
+	Attrs map[string]any
+	Up    *jsonNode
+	id    int
+}
+
+// TestJSON executes all of the tests described by jsonTests.
+func TestJSON(t *testing.T) {
+	cfgCompact := &spew.Config{DisablePointerAddresses: true, SortKeys: true}
+	cfgClean := &spew.Config{DisableTypes: true, DisableLengths: true,
+		DisablePointerAddresses: true, SortKeys: true}
+	cfgIndent := &spew.Config{Indent: " ", DisableLengths: true, DisablePointerAddresses: true}
+	cfgMaxDepth := &spew.Config{DisableTypes: true, DisableLengths: true, MaxDepth: 1}
+	cfgContinue := &spew.Config{DisableTypes: true, ContinueOnMethod: true}
+	cfgNoUnexported := &spew.Config{DisableTypes: true, DisableUnexported: true}
+
+	i := 5
+	pi := &i
+	tree := &jsonNode{Name: "root"}
+	tree.Items = []*jsonNode{{Name: "a", Up: tree}, {Name: "b", Up: tree}}
+	tree.Items[1].Items = []*jsonNode{{Name: "c", Up: tree.Items[1]}}
+
+	tests := []jsonTest{
+		{line(), cfgCompact, nil, `{"$type":"interface {}","$value":null}`},
+		{line(), cfgClean, nil, `null`},
+		{line(), cfgCompact, 1, `{"$type":"int","$value":1}`},
+		{line(), cfgCompact, "ab", `{"$type":"string","len":2,"$value":"ab"}`},
+		{line(), cfgCompact, []int{1, 2}, `{"$type":"[]int","len":2,"cap":2,"$value":[{"$type":"int","$value":1},{"$type":"int","$value":2}]}`},
+		{line(), cfgCompact, []int(nil), `{"$type":"[]int","$value":null}`},
+		{line(), cfgCompact, &pi, `{"$type":"**int","$value":5}`},
+		{line(), cfgCompact, (*int)(nil), `{"$type":"*int","$value":null}`},
+		{line(), cfgClean, "a\"\\\n\x01<>", `"a\"\\\n\u0001<>"`},
+		{line(), cfgClean, []byte("hi"), `"aGk="`},
+		{line(), cfgClean, map[int]bool{2: false, 1: true}, `{"1":true,"2":false}`},
+		{line(), cfgClean, math.NaN(), `"NaN"`},
+		{line(), cfgClean, complex(1, 2), `"(1+2i)"`},
+		{line(), cfgClean, stringer("x"), `"stringer x"`},
+		{line(), cfgClean, jsonNode{Name: "a", id: 1},
+			`{"Name":"a","Items":null,"Attrs":null,"Up":null,"id":1}`},
+		{line(), cfgNoUnexported, jsonNode{Name: "a", id: 1},
+			`{"Name":{"len":1,"$value":"a"},"Items":null,"Attrs":null,"Up":null}`},
+		{line(), cfgCompact, jsonNode{Attrs: map[string]any{"k": 1}},
+			`{"$type":"spew_test.jsonNode","Name":{"$type":"string","len":0,"$value":""},` +
+				`"Items":{"$type":"[]*spew_test.jsonNode","$value":null},` +
+				`"Attrs":{"$type":"map[string]interface {}","len":1,"$value":{"k":{"$type":"int","$value":1}}},` +
+				`"Up":{"$type":"*spew_test.jsonNode","$value":null},"id":{"$type":"int","$value":0}}`},
+		{line(), cfgClean, tree,
+			`{"Name":"root","Items":[` +
+				`{"Name":"a","Items":null,"Attrs":null,"Up":{"$ref":"#"},"id":0},` +
+				`{"Name":"b","Items":[{"Name":"c","Items":null,"Attrs":null,"Up":{"$ref":"#/Items/1"},"id":0}],` +
+				`"Attrs":null,"Up":{"$ref":"#"},"id":0}],"Attrs":null,"Up":null,"id":0}`},
+		{line(), cfgCompact, map[string]*jsonNode{"a/b": tree.Items[1].Items[0]},
+			`{"$type":"map[string]*spew_test.jsonNode","len":1,"$value":{"a/b":{"$type":"*spew_test.jsonNode",` +
+				`"Name":{"$type":"string","len":1,"$value":"c"},"Items":{"$type":"[]*spew_test.jsonNode","$value":null},` +
+				`"Attrs":{"$type":"map[string]interface {}","$value":null},"Up":{"$type":"*spew_test.jsonNode",` +
+				`"Name":{"$type":"string","len":1,"$value":"b"},"Items":{"$type":"[]*spew_test.jsonNode","len":1,"cap":1,` +
+				`"$value":[{"$ref":"#/$value/a~1b"}]},"Attrs":{"$type":"map[string]interface {}","$value":null},` +
+				`"Up":{"$type":"*spew_test.jsonNode","Name":{"$type":"string","len":4,"$value":"root"},` +
+				`"Items":{"$type":"[]*spew_test.jsonNode","len":2,"cap":2,"$value":[{"$type":"*spew_test.jsonNode",` +
+				`"Name":{"$type":"string","len":1,"$value":"a"},"Items":{"$type":"[]*spew_test.jsonNode","$value":null},` +
+				`"Attrs":{"$type":"map[string]interface {}","$value":null},"Up":{"$ref":"#/$value/a~1b/Up/Up"},` +
+				`"id":{"$type":"int","$value":0}},{"$ref":"#/$value/a~1b/Up"}]},` +
+				`"Attrs":{"$type":"map[string]interface {}","$value":null},"Up":{"$type":"*spew_test.jsonNode","$value":null},` +
+				`"id":{"$type":"int","$value":0}},"id":{"$type":"int","$value":0}},"id":{"$type":"int","$value":0}}}}`},
+		{line(), cfgIndent, []string{"a"}, "{\n \"$type\": \"[]string\",\n \"$value\": [\n  {\n   \"$type\": \"string\",\n   \"$value\": \"a\"\n  }\n ]\n}"},
+		{line(), cfgIndent, struct{}{}, "{\n \"$type\": \"struct {}\"\n}"},
+		{line(), cfgMaxDepth, [][]int{{1}}, `[` + `"<max depth reached>"` + `]`},
+		{line(), cfgContinue, stringer("x"), `{"len":1,"$string":"stringer x","$value":"x"}`},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		s := test.cfg.Sjson(test.in)
+		want := test.want + "\n"
+		if s != want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, s, want)
+			continue
+		}
+		if !json.Valid([]byte(s)) {
+			t.Errorf("testcase on line %s: invalid JSON: %s", test.line, s)
+		}
+	}
+
+	// The default config includes pointer addresses.
+	buf := new(bytes.Buffer)
+	spew.Fjson(buf, pi)
+	want := fmt.Sprintf("{\n \"$type\": \"*int\",\n \"$addrs\": [\n  \"%p\"\n ],\n \"$value\": 5\n}\n", pi)
+	if s := buf.String(); s != want {
+		t.Errorf("spew.Fjson:\n got: %s\nwant: %s", s, want)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// TestLimits executes all of the tests described by limitTests.
+func TestLimits(t *testing.T) {
+	cfg := &spew.Config{Indent: " ", DisableCapacities: true, SortKeys: true,
+		MaxElements: 2, MaxStringLen: 4}
+	cfgMiddle := &spew.Config{Indent: " ", DisableCapacities: true, SortKeys: true,
+		MaxElements: 2, MaxStringLen: 4, ElideMiddle: true}
+
+	list := []int{1, 2, 3, 4, 5}
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	buf := []byte("0123456789abcdefXYZ!")
+
+	tests := []outputTest{
+		{line(), cfg, "", list, "([]int) (len=5) {\n" +
+			" (int) 1,\n" +
+			" (int) 2,\n" +
+			" ... (3 more)\n" +
+			"}\n"},
+		{line(), cfgMiddle, "", list, "([]int) (len=5) {\n" +
+			" (int) 1,\n" +
+			" ... (3 more),\n" +
+			" (int) 5\n" +
+			"}\n"},
+		{line(), cfg, "", []int{1, 2}, "([]int) (len=2) {\n" +
+			" (int) 1,\n" +
+			" (int) 2\n" +
+			"}\n"},
+		{line(), cfg, "", m, "(map[string]int) (len=3) {\n" +
+			" (string) (len=1) \"a\": (int) 1,\n" +
+			" (string) (len=1) \"b\": (int) 2,\n" +
+			" ... (1 more)\n" +
+			"}\n"},
+		{line(), cfgMiddle, "", m, "(map[string]int) (len=3) {\n" +
+			" (string) (len=1) \"a\": (int) 1,\n" +
+			" ... (1 more),\n" +
+			" (string) (len=1) \"c\": (int) 3\n" +
+			"}\n"},
+		{line(), cfg, "", "abcdefgh", "(string) (len=8) \"abcd\" ... (4 more)\n"},
+		{line(), cfgMiddle, "", "abcdefgh", "(string) (len=8) \"ab\" ... (4 more) \"gh\"\n"},
+		{line(), cfg, "", "日本語", "(string) (len=9) \"日\" ... (6 more)\n"},
+		{line(), cfg, "", buf, "([]uint8) (len=20) {\n" +
+			" 00000000  30 31 32 33                                       |0123|\n" +
+			" ... (16 more)\n" +
+			"}\n"},
+		{line(), cfgMiddle, "", buf, "([]uint8) (len=20) {\n" +
+			" 00000000  30 31                                             |01|\n" +
+			" ... (16 more)\n" +
+			" 00000012  5a 21                                             |Z!|\n" +
+			"}\n"},
+		{line(), cfg, "%v", list, "[1 2 ... (3 more)]"},
+		{line(), cfgMiddle, "%v", list, "[1 ... (3 more) 5]"},
+		{line(), cfg, "%v", m, "map[a:1 b:2 ... (1 more)]"},
+		{line(), cfgMiddle, "%v", m, "map[a:1 ... (1 more) c:3]"},
+		{line(), cfg, "%v", "abcdefgh", "abcd ... (4 more)"},
+		{line(), cfgMiddle, "%v", "abcdefgh", "ab ... (4 more) gh"},
+		{line(), cfg, "%v", buf[:6], "[48 49 50 51 ... (2 more)]"},
+	}
+
+	runOutputTests(t, tests)
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// maxBytesStruct is used to test that MaxBytes closes open braces.
+type maxBytesStruct struct {
+	A []int
+	B string
+}
+
+// TestMaxBytes executes all of the tests described by maxBytesTests.
+func TestMaxBytes(t *testing.T) {
+	cfg := &spew.Config{Indent: " ", DisableCapacities: true, MaxBytes: 40}
+	cfgMore := &spew.Config{Indent: " ", DisableCapacities: true, MaxBytes: 1000}
+	cfgLess := &spew.Config{Indent: " ", DisableCapacities: true, MaxBytes: 30}
+	cfgPtr := &spew.Config{Indent: " ", DisableCapacities: true, DisablePointerAddresses: true, MaxBytes: 75}
+	cfgTiny := &spew.Config{MaxBytes: 5}
+
+	v := maxBytesStruct{A: []int{1, 2, 3}, B: "hello"}
+	long := strings.Repeat("x", 100)
+
+	tests := []outputTest{
+		{line(), cfg, "", v, "(spew_test.maxBytesStruct) {\n" +
+			" A: ([]int) (len=3) {\n" +
+			" }\n" +
+			"}\n" +
+			"<truncated after 56 bytes>\n"},
+		{line(), cfgMore, "", v, "(spew_test.maxBytesStruct) {\n" +
+			" A: ([]int) (len=3) {\n" +
+			"  (int) 1,\n" +
+			"  (int) 2,\n" +
+			"  (int) 3\n" +
+			" },\n" +
+			" B: (string) (len=5) \"hello\"\n" +
+			"}\n"},
+		{line(), cfg, "", long, "(string) (len=100) \"xxxxxxxxxxxxxxxxxxxxx\" ... (79 more)\n" +
+			"<truncated after 57 bytes>\n"},
+		{line(), cfg, "%v", []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20},
+			"[1 2 3 4 5 6 7 8 9 10 11 12 13 14 15 16 17] <truncated after 43 bytes>"},
+		{line(), cfg, "%+v", map[string]string{"a": long}, "map[a:" + long[:34] +
+			" ... (66 more)] <truncated after 55 bytes>"},
+		{line(), cfg, "%v", long, long[:40] + " ... (60 more) <truncated after 54 bytes>"},
+		{line(), cfgLess, "%v", long, long[:30] + " ... (70 more) <truncated after 44 bytes>"},
+		{line(), cfgLess, "", []int{1, 2, 3, 4, 5}, "([]int) (len=5) {\n" +
+			" (int) 1,\n" +
+			" (int) 2\n" +
+			"}\n" +
+			"<truncated after 39 bytes>\n"},
+		{line(), cfgMore, "%v", v, "{[1 2 3] hello}"},
+		// No comma is left behind by the last element which was output.
+		{line(), cfgPtr, "", &maxBytesStruct{A: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+			"(*spew_test.maxBytesStruct)({\n" +
+				" A: ([]int) (len=10) {\n" +
+				"  (int) 1,\n" +
+				"  (int) 2,\n" +
+				"  (int) 3\n" +
+				" }\n" +
+				"})\n" +
+				"<truncated after 91 bytes>\n"},
+	}
+
+	runOutputTests(t, tests)
+
+	// The budget is shared by all of the arguments.
+	want := "(int) 1\n(int) 2\n(int) 3\n(int) 4\n(int) 5\n<truncated after 40 bytes>\n"
+	if s := cfg.Sdump(1, 2, 3, 4, 5, 6, 7); s != want {
+		t.Errorf("Sdump:\n got: %s\nwant: %s", s, want)
+	}
+
+	// The Formatter is invoked for each operand on its own, so each one has a
+	// budget of its own.
+	want = "hello ... (6 more) <truncated after 18 bytes> 1 2"
+	if s := cfgTiny.Sprint("hello world", 1, 2); s != want {
+		t.Errorf("Sprint:\n got: %s\nwant: %s", s, want)
+	}
+}
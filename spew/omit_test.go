@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// omitFoo is used as the elements of omitStruct.List.
+type omitFoo struct {
+	N int
+}
+
+// omitStruct is a sparse struct with nil and zero fields.
+type omitStruct struct {
+	Name  string
+	Ptr   *omitFoo
+	Any   any
+	List  []*omitFoo
+	Count int
+	Last  string
+}
+
+// TestOmit executes all of the tests described by omitTests.
+func TestOmit(t *testing.T) {
+	cfgNil := &spew.Config{Indent: " ", DisablePointerAddresses: true,
+		DisableCapacities: true, SortKeys: true, OmitNilFields: true}
+	cfgZero := &spew.Config{Indent: " ", DisablePointerAddresses: true,
+		DisableCapacities: true, SortKeys: true, OmitZeroFields: true}
+
+	one := 1
+	in := omitStruct{Name: "a", Any: (*omitFoo)(nil), List: []*omitFoo{{N: 1}, nil}}
+	m := map[string]*int{"a": nil, "b": &one}
+
+	tests := []outputTest{
+		{line(), cfgNil, "", in, "(spew_test.omitStruct) {\n" +
+			" Name: (string) (len=1) \"a\",\n" +
+			" List: ([]*spew_test.omitFoo) (len=2) {\n" +
+			"  (*spew_test.omitFoo)({\n" +
+			"   N: (int) 1\n" +
+			"  }),\n" +
+			"  (*spew_test.omitFoo)(<nil>)\n" +
+			" },\n" +
+			" Count: (int) 0,\n" +
+			" Last: (string) \"\"\n" +
+			"}\n"},
+		{line(), cfgZero, "", in, "(spew_test.omitStruct) {\n" +
+			" Name: (string) (len=1) \"a\",\n" +
+			" Any: (*spew_test.omitFoo)(<nil>),\n" +
+			" List: ([]*spew_test.omitFoo) (len=2) {\n" +
+			"  (*spew_test.omitFoo)({\n" +
+			"   N: (int) 1\n" +
+			"  }),\n" +
+			"  (*spew_test.omitFoo)(<nil>)\n" +
+			" }\n" +
+			"}\n"},
+		{line(), cfgNil, "", m, "(map[string]*int) (len=2) {\n" +
+			" (string) (len=1) \"b\": (*int)(1)\n" +
+			"}\n"},
+		{line(), cfgZero, "", map[string]int{"a": 0, "b": 2, "c": 0}, "(map[string]int) (len=3) {\n" +
+			" (string) (len=1) \"b\": (int) 2\n" +
+			"}\n"},
+		{line(), cfgNil, "%v", in, "{a [<*>{1} <nil>] 0 }"},
+		{line(), cfgZero, "%v", in, "{a <nil> [<*>{1} <nil>]}"},
+		{line(), cfgNil, "%v", m, "map[b:<*>1]"},
+		{line(), cfgZero, "%v", []int{0, 1}, "[0 1]"},
+	}
+
+	runOutputTests(t, tests)
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// ordNode is used to test shared and circular pointers with the
+// PointerOrdinals option.
+type ordNode struct {
+	Name string
+	A, B *ordNode
+}
+
+// TestPointerOrdinals ensures pointer addresses are replaced by ordinals
+// which are assigned in traversal order.
+func TestPointerOrdinals(t *testing.T) {
+	cfg := &spew.Config{Indent: " ", PointerOrdinals: true}
+
+	shared := &ordNode{Name: "s"}
+	root := &ordNode{Name: "r", A: shared, B: shared}
+	cyclic := &ordNode{Name: "c"}
+	cyclic.A = cyclic
+	x := 5
+	px := &x
+
+	tests := []struct {
+		line string // use line() to fill this
+		got  string
+		want string
+	}{
+		{line(), cfg.Sdump(root), "(*spew_test.ordNode)(&1)({\n" +
+			" Name: (string) (len=1) \"r\",\n" +
+			" A: (*spew_test.ordNode)(&2)({\n" +
+			"  Name: (string) (len=1) \"s\",\n" +
+			"  A: (*spew_test.ordNode)(<nil>),\n" +
+			"  B: (*spew_test.ordNode)(<nil>)\n" +
+			" }),\n" +
+			" B: (*spew_test.ordNode)(&2)({\n" +
+			"  Name: (string) (len=1) \"s\",\n" +
+			"  A: (*spew_test.ordNode)(<nil>),\n" +
+			"  B: (*spew_test.ordNode)(<nil>)\n" +
+			" })\n" +
+			"})\n"},
+		{line(), cfg.Sdump(cyclic), "(*spew_test.ordNode)(&1)({\n" +
+			" Name: (string) (len=1) \"c\",\n" +
+			" A: (*spew_test.ordNode)(&1)(<already shown>),\n" +
+			" B: (*spew_test.ordNode)(<nil>)\n" +
+			"})\n"},
+		{line(), cfg.Sdump(&px, px), "(**int)(&1->&2)(5)\n(*int)(&2)(5)\n"},
+		{line(), cfg.Sprintf("%+v", root), "<*>(&1){Name:r A:<*>(&2){Name:s A:<nil> B:<nil>} B:<*>(&2){Name:s A:<nil> B:<nil>}}"},
+		{line(), cfg.Sprintf("%+v %+v", px, &px), "<*>(&1)5 <**>(&1->&2)5"},
+		{line(), cfg.Sprintf("%#+v", cyclic), "(*spew_test.ordNode)(&1){Name:(string)c A:(*spew_test.ordNode)(&1)<shown> B:(*spew_test.ordNode)<nil>}"},
+	}
+
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, test.got, test.want)
+		}
+	}
+}
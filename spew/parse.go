@@ -0,0 +1,498 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// addrsRE matches a pointer chain as shown by Dump, with either
+	// addresses or the ordinals shown by the PointerOrdinals option.
+	addrsRE = regexp.MustCompile(`^(0x[0-9a-f]+|&[0-9]+)(->(0x[0-9a-f]+|&[0-9]+))*$`)
+
+	// lengthsRE matches the length and capacity shown by Dump.
+	lengthsRE = regexp.MustCompile(`^(len=[0-9]+ cap=[0-9]+|len=[0-9]+|cap=[0-9]+)$`)
+
+	// complexRE matches a complex number as shown by Dump.  It is used to
+	// tell complex numbers apart from types when types are disabled.
+	complexRE = regexp.MustCompile(`^[-+]?[0-9NI][0-9a-zA-Z.+-]*i$`)
+
+	// fieldRE matches the name of a struct field as shown by Dump.
+	fieldRE = regexp.MustCompile(`^[\p{L}_][\p{L}\p{N}_]*: `)
+
+	// hexLineRE matches a line of a hexdump as shown by Dump.
+	hexLineRE = regexp.MustCompile(`^[ \t]*[0-9a-f]{8}  [0-9a-f]{2} `)
+)
+
+// NodeKind identifies the kind of value a Node represents.
+type NodeKind int
+
+const (
+	// ScalarNode is a value shown inline, such as a number, a bool, an
+	// address or the output of an error or Stringer interface.
+	ScalarNode NodeKind = iota
+
+	// StringNode is a quoted string.
+	StringNode
+
+	// NilNode is a nil value, shown as <nil>.
+	NilNode
+
+	// InvalidNode is an invalid value, shown as <invalid>.
+	InvalidNode
+
+	// ListNode is an array or slice.
+	ListNode
+
+	// MapNode is a map.
+	MapNode
+
+	// StructNode is a struct.
+	StructNode
+
+	// BytesNode is an array or slice of bytes shown as a hexdump.
+	BytesNode
+
+	// CircularNode is a pointer to a value which was already shown further
+	// up, shown as <already shown>.
+	CircularNode
+
+	// MaxDepthNode stands in for the contents of a value which were not
+	// shown due to the MaxDepth option, shown as <max depth reached>.
+	MaxDepthNode
+)
+
+// nodeKindStrings maps NodeKind values to their names.
+var nodeKindStrings = map[NodeKind]string{
+	ScalarNode:   "ScalarNode",
+	StringNode:   "StringNode",
+	NilNode:      "NilNode",
+	InvalidNode:  "InvalidNode",
+	ListNode:     "ListNode",
+	MapNode:      "MapNode",
+	StructNode:   "StructNode",
+	BytesNode:    "BytesNode",
+	CircularNode: "CircularNode",
+	MaxDepthNode: "MaxDepthNode",
+}
+
+// String returns the NodeKind as a human-readable name.
+func (k NodeKind) String() string {
+	if s, ok := nodeKindStrings[k]; ok {
+		return s
+	}
+	return fmt.Sprintf("NodeKind(%d)", int(k))
+}
+
+// Node is a value parsed from Dump output by ParseDump.
+type Node struct {
+	// Kind is the kind of value the node represents.
+	Kind NodeKind
+
+	// Type is the type shown for the value, including an asterisk for each
+	// pointer which was followed to reach it.  It is empty if types were
+	// disabled.
+	Type string
+
+	// Addrs holds the addresses of the pointers which were followed to
+	// reach the value, or their ordinals such as &1, as shown by Dump.
+	Addrs []string
+
+	// Len and Cap are the length and capacity shown for the value.  Dump
+	// does not show them when they are zero.
+	Len, Cap int
+
+	// Value holds the text of a ScalarNode, or the unquoted contents of a
+	// StringNode.
+	Value string
+
+	// Bytes holds the contents of a BytesNode.
+	Bytes []byte
+
+	// Name holds the field name of a child of a StructNode, and Key holds
+	// the key of a child of a MapNode.
+	Name string
+	Key  *Node
+
+	// Children holds the elements of a ListNode, the values of a MapNode or
+	// the fields of a StructNode in the order they were shown.  A value
+	// whose contents were not shown due to the MaxDepth option has a single
+	// MaxDepthNode child.
+	Children []*Node
+}
+
+// parseState contains information about the state of a parse operation.
+type parseState struct {
+	s   string
+	pos int
+}
+
+// errorf returns an error describing a problem at the current position.
+func (p *parseState) errorf(format string, a ...any) error {
+	line := 1 + strings.Count(p.s[:p.pos], "\n")
+	return fmt.Errorf("spew: line %d: %s", line, fmt.Sprintf(format, a...))
+}
+
+// peek returns whether the text at the current position starts with prefix.
+func (p *parseState) peek(prefix string) bool {
+	return strings.HasPrefix(p.s[p.pos:], prefix)
+}
+
+// consume advances past prefix and returns true if the text at the current
+// position starts with it.
+func (p *parseState) consume(prefix string) bool {
+	if p.peek(prefix) {
+		p.pos += len(prefix)
+		return true
+	}
+	return false
+}
+
+// skipWhitespace advances past any spaces, tabs and newlines.
+func (p *parseState) skipWhitespace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// group returns the contents of the parenthesized group at the current
+// position, and the position just past it.  Groups never span lines.
+func (p *parseState) group() (string, int, bool) {
+	if !p.peek("(") {
+		return "", 0, false
+	}
+	depth := 0
+	for i := p.pos; i < len(p.s); i++ {
+		switch p.s[i] {
+		case '\n':
+			return "", 0, false
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return p.s[p.pos+1 : i], i + 1, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// isTerminator returns whether the text at the current position ends a value.
+func (p *parseState) isTerminator(inParen, inKey bool) bool {
+	return p.pos == len(p.s) || p.peek("\n") || p.peek(",\n") ||
+		(inParen && p.peek(")")) || (inKey && p.peek(": "))
+}
+
+// parseScalar returns the text of the scalar at the current position.  The
+// scalar ends at the end of the line, or at the end of an enclosing pointer
+// or map key, whichever comes first.
+func (p *parseState) parseScalar(inParen, inKey bool) string {
+	start := p.pos
+	depth := 0
+	for ; p.pos < len(p.s); p.pos++ {
+		if depth == 0 && p.isTerminator(inParen, inKey) {
+			break
+		}
+		switch p.s[p.pos] {
+		case '\n':
+			return p.s[start:p.pos]
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+	}
+	return p.s[start:p.pos]
+}
+
+// parseValue parses the value at the current position, including its type
+// and any pointers which were followed to reach it.
+func (p *parseState) parseValue(inParen, inKey bool) (*Node, error) {
+	n := &Node{}
+
+	// Types can't be told apart from some other parenthesized values when
+	// types are disabled, so rule those out first.
+	typ, end, ok := p.group()
+	if !ok || typ == "" || addrsRE.MatchString(typ) || lengthsRE.MatchString(typ) ||
+		complexRE.MatchString(typ) || strings.HasPrefix(typ, "PANIC=") {
+		return n, p.parseBody(n, inParen, inKey)
+	}
+	n.Type = typ
+	p.pos = end
+
+	// Values which are not pointers are separated from their types by a
+	// space.
+	if !p.peek("(") {
+		if !p.consume(" ") {
+			return nil, p.errorf("expected space after type (%s)", typ)
+		}
+		return n, p.parseBody(n, inParen, inKey)
+	}
+
+	// The pointer chain is only shown if pointer addresses are enabled, and
+	// is always followed by the dereferenced value.
+	if addrs, end, ok := p.group(); ok && addrsRE.MatchString(addrs) &&
+		strings.HasPrefix(p.s[end:], "(") {
+		n.Addrs = strings.Split(addrs, string(pointerChainBytes))
+		p.pos = end
+	}
+	p.pos++
+	switch {
+	case p.consume(string(nilAngleBytes)):
+		n.Kind = NilNode
+
+	case p.consume(string(circularBytes)):
+		n.Kind = CircularNode
+
+	default:
+		if err := p.parseBody(n, true, false); err != nil {
+			return nil, err
+		}
+	}
+	if !p.consume(")") {
+		return nil, p.errorf("expected ) after pointer value")
+	}
+	return n, nil
+}
+
+// parseBody parses the value at the current position once its type, if any,
+// has been parsed.
+func (p *parseState) parseBody(n *Node, inParen, inKey bool) error {
+	// Parse the length and capacity if they were shown.
+	if lengths, end, ok := p.group(); ok && lengthsRE.MatchString(lengths) {
+		for _, f := range strings.Fields(lengths) {
+			i, _ := strconv.Atoi(f[4:])
+			if strings.HasPrefix(f, string(lenEqualsBytes)) {
+				n.Len = i
+			} else {
+				n.Cap = i
+			}
+		}
+		p.pos = end
+		if !p.consume(" ") {
+			return p.errorf("expected space after (%s)", lengths)
+		}
+	}
+
+	switch {
+	case p.consume(string(nilAngleBytes)):
+		n.Kind = NilNode
+
+	case p.consume(string(invalidAngleBytes)):
+		n.Kind = InvalidNode
+
+	case p.consume(string(emptyBracesBytes)):
+		n.Kind = kindFromType(n.Type, StructNode)
+
+	case p.consume(string(emptyListBytes)):
+		n.Kind = ListNode
+
+	case p.consume(string(openBraceNewlineBytes)):
+		return p.parseBlock(n, closeBraceBytes)
+
+	case p.consume(string(openListNewlineBytes)):
+		return p.parseBlock(n, closeListBytes)
+
+	default:
+		// Output from error and Stringer interfaces may start with a quote
+		// without being a quoted string.
+		start := p.pos
+		if q, err := strconv.QuotedPrefix(p.s[p.pos:]); err == nil {
+			p.pos += len(q)
+			if p.isTerminator(inParen, inKey) {
+				n.Kind = StringNode
+				n.Value, _ = strconv.Unquote(q)
+				return nil
+			}
+			p.pos = start
+		}
+		n.Kind = ScalarNode
+		n.Value = p.parseScalar(inParen, inKey)
+		if n.Value == "" {
+			return p.errorf("expected value")
+		}
+	}
+	return nil
+}
+
+// kindFromType returns the kind of collection a value of type typ is, or def
+// if it can't be determined from the type alone.  Named types don't show
+// what kind of collection they are.
+func kindFromType(typ string, def NodeKind) NodeKind {
+	typ = strings.TrimLeft(typ, "*")
+	switch {
+	case strings.HasPrefix(typ, "["):
+		return ListNode
+	case strings.HasPrefix(typ, "map["):
+		return MapNode
+	case strings.HasPrefix(typ, "struct {"):
+		return StructNode
+	}
+	return def
+}
+
+// parseBlock parses the entries of an array, slice, map or struct up to and
+// including the closing brace.  When the kind of collection can't be told
+// from the type, it is determined from the first entry.
+func (p *parseState) parseBlock(n *Node, closeBytes []byte) error {
+	closing := string(closeBytes)
+	if hexLineRE.MatchString(p.s[p.pos:]) {
+		return p.parseHexdump(n, closing)
+	}
+
+	const unknown = NodeKind(-1)
+	kind := kindFromType(n.Type, unknown)
+	if kind == unknown && closing == string(closeListBytes) {
+		kind = ListNode
+	}
+	defer func() {
+		n.Kind = kind
+		if kind == unknown {
+			n.Kind = ListNode
+		}
+	}()
+
+	p.skipWhitespace()
+	if p.consume(string(maxNewlineBytes[:len(maxNewlineBytes)-1])) {
+		n.Children = []*Node{{Kind: MaxDepthNode}}
+		p.skipWhitespace()
+		if !p.consume(closing) {
+			return p.errorf("expected %s after max depth", closing)
+		}
+		return nil
+	}
+
+	for {
+		p.skipWhitespace()
+		if p.pos == len(p.s) {
+			return p.errorf("unexpected end of input, expected %s", closing)
+		}
+		if p.consume(closing) {
+			return nil
+		}
+
+		name := ""
+		if kind == StructNode || kind == unknown {
+			if f := fieldRE.FindString(p.s[p.pos:]); f != "" {
+				name = f[:len(f)-len(colonSpaceBytes)]
+				p.pos += len(f)
+				kind = StructNode
+			} else if kind == StructNode {
+				return p.errorf("expected field name")
+			}
+		}
+
+		child, err := p.parseValue(false, kind == MapNode || kind == unknown)
+		if err != nil {
+			return err
+		}
+		child.Name = name
+		if kind == MapNode || kind == unknown {
+			if p.consume(string(colonSpaceBytes)) {
+				kind = MapNode
+				key := child
+				if child, err = p.parseValue(false, false); err != nil {
+					return err
+				}
+				child.Key = key
+			} else if kind == MapNode {
+				return p.errorf("expected : after map key")
+			} else {
+				kind = ListNode
+			}
+		}
+		n.Children = append(n.Children, child)
+
+		p.consume(string(commaBytes))
+		if !p.consume(string(newlineBytes)) {
+			return p.errorf("expected newline after entry")
+		}
+	}
+}
+
+// parseHexdump parses the lines of a hexdump up to and including the closing
+// brace.
+func (p *parseState) parseHexdump(n *Node, closing string) error {
+	n.Kind = BytesNode
+	n.Bytes = []byte{}
+	for p.pos < len(p.s) {
+		line := p.s[p.pos:]
+		if i := strings.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i+1]
+		}
+		if strings.HasPrefix(strings.TrimLeft(line, " \t"), closing) {
+			p.skipWhitespace()
+			p.pos += len(closing)
+			return nil
+		}
+		if !hexLineRE.MatchString(line) {
+			return p.errorf("expected hexdump line")
+		}
+
+		// The offset is followed by the bytes and then the printable
+		// characters between vertical bars.
+		fields := strings.Fields(line)
+		for _, f := range fields[1:] {
+			if strings.HasPrefix(f, "|") {
+				break
+			}
+			b, err := hex.DecodeString(f)
+			if err != nil || len(b) != 1 {
+				return p.errorf("invalid hexdump byte %q", f)
+			}
+			n.Bytes = append(n.Bytes, b[0])
+		}
+		p.pos += len(line)
+	}
+	return p.errorf("unexpected end of input, expected %s", closing)
+}
+
+// ParseDump parses the output of Dump for a single value back into a tree of
+// Nodes, so that dumps kept in logs and bug reports can be processed
+// programmatically.  Every construct Dump emits is understood, with any
+// Indent, and with any of the options which disable parts of the output.
+//
+// Dump output is meant for humans, so it is ambiguous in places.  When types
+// are disabled, or a collection has a named type, whether it is a list, map
+// or struct is determined from its entries.  Ones without entries are assumed
+// to be lists, unless abbreviated as {}.  The output of error and Stringer
+// interfaces is returned as a ScalarNode, and may be misparsed if it spans
+// lines or looks like some other construct.
+func ParseDump(r io.Reader) (*Node, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := parseState{s: strings.ReplaceAll(string(b), "\r\n", "\n")}
+	p.skipWhitespace()
+	n, err := p.parseValue(false, false)
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhitespace()
+	if p.pos != len(p.s) {
+		return nil, p.errorf("unexpected text after value")
+	}
+	return n, nil
+}
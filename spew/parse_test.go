@@ -0,0 +1,199 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// parseTest is used to describe a test to be performed against the ParseDump
+// function.  The input is dumped with cfg and then parsed back.
+type parseTest struct {
+	line string // use line() to fill this
+	cfg  *spew.Config
+	in   any
+	want string
+}
+
+// parseNode is used to test structs, nesting and circular references.
+type parseNode struct {
+	Name  string
+	Attrs map[string]any
+	Next  *parseNode
+	data  []byte
+}
+
+// nodeString renders a Node tree compactly so that it can be compared.
+// Addresses are replaced by their count since they vary between runs.
+func nodeString(n *spew.Node) string {
+	s := ""
+	if n.Type != "" {
+		s += "(" + n.Type + ")"
+	}
+	if len(n.Addrs) > 0 {
+		s += fmt.Sprintf("@%d", len(n.Addrs))
+	}
+	if n.Len != 0 || n.Cap != 0 {
+		s += fmt.Sprintf("<%d,%d>", n.Len, n.Cap)
+	}
+	switch n.Kind {
+	case spew.ScalarNode:
+		s += n.Value
+	case spew.StringNode:
+		s += strconv.Quote(n.Value)
+	case spew.BytesNode:
+		s += fmt.Sprintf("%x", n.Bytes)
+	case spew.ListNode, spew.MapNode, spew.StructNode:
+		children := make([]string, 0, len(n.Children))
+		for _, c := range n.Children {
+			switch {
+			case c.Name != "":
+				children = append(children, c.Name+": "+nodeString(c))
+			case c.Key != nil:
+				children = append(children, nodeString(c.Key)+": "+nodeString(c))
+			default:
+				children = append(children, nodeString(c))
+			}
+		}
+		s += n.Kind.String() + "{" + strings.Join(children, ", ") + "}"
+	default:
+		s += n.Kind.String()
+	}
+	return s
+}
+
+// TestParseDump executes all of the tests described by parseTests.
+func TestParseDump(t *testing.T) {
+	cfgDefault := &spew.Config{Indent: " ", SortKeys: true}
+	cfgTabs := &spew.Config{Indent: "\t", SortKeys: true, TrailingCommas: true,
+		DumpListSquareBraces: true}
+	cfgClean := &spew.Config{Indent: "  ", SortKeys: true, DisableTypes: true,
+		DisableLengths: true, DisablePointerAddresses: true}
+	cfgAbbrev := &spew.Config{Indent: " ", AbbreviateEmpty: true}
+	cfgMaxDepth := &spew.Config{Indent: " ", MaxDepth: 1}
+	cfgNoMethods := &spew.Config{Indent: " ", DisableMethods: true}
+	cfgOrdinals := &spew.Config{Indent: " ", PointerOrdinals: true}
+
+	i := 5
+	pi := &i
+	var nilPtr *int
+	var iface any
+	c := complex64(1 + 2i)
+	circ := &parseNode{Name: "circ"}
+	circ.Next = circ
+	tree := parseNode{Name: "root", Attrs: map[string]any{"a": 1, "b": []string{"x"}},
+		data: []byte("hi")}
+
+	tests := []parseTest{
+		{line(), cfgDefault, nil, "(interface {})NilNode"},
+		{line(), cfgDefault, 5, "(int)5"},
+		{line(), cfgClean, 5, "5"},
+		{line(), cfgDefault, -1.5, "(float64)-1.5"},
+		{line(), cfgDefault, complex(1, -2), "(complex128)(1-2i)"},
+		{line(), cfgClean, complex(1, -2), "(1-2i)"},
+		{line(), cfgDefault, "a \"b\"\n", `(string)<6,0>"a \"b\"\n"`},
+		{line(), cfgDefault, &pi, "(**int)@25"},
+		{line(), cfgClean, &pi, "(**int)5"},
+		{line(), cfgDefault, nilPtr, "(*int)NilNode"},
+		{line(), cfgDefault, &iface, "(*interface {})@1NilNode"},
+		{line(), cfgDefault, &c, "(*complex64)@1(1+2i)"},
+		{line(), cfgDefault, []int{1, 2}, "([]int)<2,2>ListNode{(int)1, (int)2}"},
+		{line(), cfgTabs, []int{1, 2}, "([]int)<2,2>ListNode{(int)1, (int)2}"},
+		{line(), cfgClean, []int{1, 2}, "ListNode{1, 2}"},
+		{line(), cfgDefault, []int(nil), "([]int)NilNode"},
+		{line(), cfgDefault, []int{}, "([]int)ListNode{}"},
+		{line(), cfgAbbrev, []int{}, "([]int)ListNode{}"},
+		{line(), cfgAbbrev, map[int]int{}, "(map[int]int)MapNode{}"},
+		{line(), cfgDefault, []byte("hello world, hi!!"),
+			"([]uint8)<17,17>68656c6c6f20776f726c642c2068692121"},
+		{line(), cfgTabs, [2]byte{1, 2}, "([2]uint8)<2,2>0102"},
+		{line(), cfgDefault, map[int]string{2: "b", 1: "a"},
+			`(map[int]string)<2,0>MapNode{(int)1: (string)<1,0>"a", (int)2: (string)<1,0>"b"}`},
+		{line(), cfgClean, map[int]string{2: "b", 1: "a"}, `MapNode{1: "a", 2: "b"}`},
+		{line(), cfgClean, map[stringer]int{"a": 1}, `MapNode{stringer a: 1}`},
+		{line(), cfgDefault, stringer("a"), "(spew_test.stringer)<1,0>stringer a"},
+		{line(), cfgNoMethods, stringer("a"), `(spew_test.stringer)<1,0>"a"`},
+		{line(), cfgDefault, panicer(1), "(spew_test.panicer)(PANIC=test panic)1"},
+		{line(), cfgDefault, customError(1), "(spew_test.customError)error: 1"},
+		{line(), cfgDefault, tree,
+			`(spew_test.parseNode)StructNode{Name: (string)<4,0>"root", ` +
+				`Attrs: (map[string]interface {})<2,0>MapNode{` +
+				`(string)<1,0>"a": (int)1, ` +
+				`(string)<1,0>"b": ([]string)<1,1>ListNode{(string)<1,0>"x"}}, ` +
+				`Next: (*spew_test.parseNode)NilNode, data: ([]uint8)<2,2>6869}`},
+		{line(), cfgClean, tree,
+			`StructNode{Name: "root", Attrs: MapNode{"a": 1, "b": ListNode{"x"}}, ` +
+				`Next: (*spew_test.parseNode)NilNode, data: 6869}`},
+		{line(), cfgDefault, circ,
+			`(*spew_test.parseNode)@1StructNode{Name: (string)<4,0>"circ", ` +
+				`Attrs: (map[string]interface {})NilNode, ` +
+				`Next: (*spew_test.parseNode)@1CircularNode, data: ([]uint8)NilNode}`},
+		{line(), cfgOrdinals, &pi, "(**int)@25"},
+		{line(), cfgOrdinals, circ,
+			`(*spew_test.parseNode)@1StructNode{Name: (string)<4,0>"circ", ` +
+				`Attrs: (map[string]interface {})NilNode, ` +
+				`Next: (*spew_test.parseNode)@1CircularNode, data: ([]uint8)NilNode}`},
+		{line(), cfgMaxDepth, tree,
+			`(spew_test.parseNode)StructNode{Name: (string)<4,0>"root", ` +
+				`Attrs: (map[string]interface {})<2,0>MapNode{MaxDepthNode}, ` +
+				`Next: (*spew_test.parseNode)NilNode, data: ([]uint8)<2,2>ListNode{MaxDepthNode}}`},
+		{line(), cfgDefault, map[*int]int{pi: 1}, "(map[*int]int)<1,0>MapNode{(*int)@15: (int)1}"},
+		{line(), cfgDefault, struct{}{}, "(struct {})StructNode{}"},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		dump := test.cfg.Sdump(test.in)
+		n, err := spew.ParseDump(strings.NewReader(dump))
+		if err != nil {
+			t.Errorf("testcase on line %s: %v\n%s", test.line, err, dump)
+			continue
+		}
+		s := nodeString(n)
+		if s != test.want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, s, test.want)
+		}
+	}
+}
+
+// TestParseDumpErrors ensures ParseDump reports malformed input.
+func TestParseDumpErrors(t *testing.T) {
+	tests := []struct {
+		line string // use line() to fill this
+		in   string
+		want string
+	}{
+		{line(), "", "spew: line 1: expected value"},
+		{line(), "(int)5", "spew: line 1: expected space after type (int)"},
+		{line(), "(*int)(0xc000012345)(5", "spew: line 1: expected ) after pointer value"},
+		{line(), "([]int) {\n (int) 1,\n", "spew: line 3: unexpected end of input, expected }"},
+		{line(), "(int) 5\n(int) 6\n", "spew: line 2: unexpected text after value"},
+		{line(), "([]uint8) {\n 00000000  01 0g  |..|\n}", `spew: line 2: invalid hexdump byte "0g"`},
+	}
+
+	for _, test := range tests {
+		_, err := spew.ParseDump(strings.NewReader(test.in))
+		if err == nil || err.Error() != test.want {
+			t.Errorf("testcase on line %s:\n got: %v\nwant: %s", test.line, err, test.want)
+		}
+	}
+}
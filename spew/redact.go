@@ -0,0 +1,66 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// redactName reports whether the value of the struct field or map entry with
+// the passed name, found at path, should be redacted according to the Redact
+// option of cfg.
+func redactName(cfg *Config, name, path string) bool {
+	for _, re := range cfg.Redact {
+		if re.MatchString(name) || re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactString reports whether v is a string which should be redacted
+// according to the RedactValues option of cfg.
+func redactString(cfg *Config, v reflect.Value) bool {
+	if v.Kind() != reflect.String {
+		return false
+	}
+	for _, re := range cfg.RedactValues {
+		if re.MatchString(v.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactedLen returns the marker which is output in place of the redacted
+// value v.  It includes the length of v if it has one.
+func redactedLen(v reflect.Value) []byte {
+	switch v.Kind() {
+	case reflect.Array, reflect.Chan, reflect.Map, reflect.Slice, reflect.String:
+		return []byte("<redacted len=" + strconv.Itoa(v.Len()) + ">")
+	}
+	return redactedBytes
+}
+
+// redactedKey returns the name which is output in place of the redacted string
+// map key v, which is entry i of its map.  The index keeps the names of
+// redacted keys of the same length distinct, since formats such as JSON and
+// YAML don't allow duplicate keys.
+func redactedKey(v reflect.Value, i int) string {
+	return "<redacted len=" + strconv.Itoa(v.Len()) + " #" + strconv.Itoa(i) + ">"
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// redactLogin has fields which are redacted by name, path and value.
+type redactLogin struct {
+	User     string
+	Password string
+	Tokens   []string
+	Extra    map[string]string
+	Inner    redactInner
+	Note     string
+}
+
+// redactInner has a field which is redacted by its path.
+type redactInner struct {
+	Code int
+}
+
+// TestRedact executes all of the tests described by redactTests.
+func TestRedact(t *testing.T) {
+	cfg := &spew.Config{Indent: " ", DisableCapacities: true, SortKeys: true,
+		Redact: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)password|token`),
+			regexp.MustCompile(`^\.Inner\.Code$`),
+		},
+		RedactValues: []*regexp.Regexp{regexp.MustCompile(`^sk-`)},
+	}
+
+	in := redactLogin{
+		User:     "bob",
+		Password: "hunter2",
+		Tokens:   []string{"a", "b"},
+		Extra:    map[string]string{"api_token": "t1", "key": "sk-abc", "region": "eu"},
+		Inner:    redactInner{Code: 1234},
+		Note:     "sk-123",
+	}
+
+	tests := []outputTest{
+		{line(), cfg, "", in, "(spew_test.redactLogin) {\n" +
+			" User: (string) (len=3) \"bob\",\n" +
+			" Password: (string) <redacted len=7>,\n" +
+			" Tokens: ([]string) <redacted len=2>,\n" +
+			" Extra: (map[string]string) (len=3) {\n" +
+			"  (string) (len=9) \"api_token\": (string) <redacted len=2>,\n" +
+			"  (string) (len=3) \"key\": (string) <redacted len=6>,\n" +
+			"  (string) (len=6) \"region\": (string) (len=2) \"eu\"\n" +
+			" },\n" +
+			" Inner: (spew_test.redactInner) {\n" +
+			"  Code: (int) <redacted>\n" +
+			" },\n" +
+			" Note: (string) <redacted len=6>\n" +
+			"}\n"},
+		{line(), cfg, "", map[string]int{"sk-key": 1}, "(map[string]int) (len=1) {\n" +
+			" (string) <redacted len=6>: (int) 1\n" +
+			"}\n"},
+		{line(), cfg, "", map[string]any{"Password": []byte("pw")}, "(map[string]interface {}) (len=1) {\n" +
+			" (string) (len=8) \"Password\": ([]uint8) <redacted len=2>\n" +
+			"}\n"},
+		{line(), cfg, "%v", in, "{bob <redacted len=7> <redacted len=2> " +
+			"map[api_token:<redacted len=2> key:<redacted len=6> region:eu] {<redacted>} <redacted len=6>}"},
+		{line(), cfg, "%+v", redactInner{Code: 1}, "{Code:1}"},
+		{line(), cfg, "%#v", map[string]any{"password": "x"}, "(map[string]interface {})map[password:(string)<redacted len=1>]"},
+		{line(), cfg, "%#v", redactLogin{Password: "pw"}, "(spew_test.redactLogin){User:(string) Password:(string)<redacted len=2> " +
+			"Tokens:([]string)<redacted len=0> Extra:(map[string]string)<nil> Inner:(spew_test.redactInner){Code:(int)<redacted>} Note:(string)}"},
+	}
+
+	runOutputTests(t, tests)
+}
+
+// TestRedactFormats ensures the Redact and RedactValues options are honored
+// by the output formats which don't share the Dump walker.
+func TestRedactFormats(t *testing.T) {
+	cfg := &spew.Config{Indent: " ", DisableTypes: true, DisableLengths: true,
+		DisablePointerAddresses: true, SortKeys: true,
+		Redact: []*regexp.Regexp{
+			regexp.MustCompile(`(?i)password|token`),
+			regexp.MustCompile(`^\.Inner\.Code$`),
+		},
+		RedactValues: []*regexp.Regexp{regexp.MustCompile(`^sk-`)},
+	}
+
+	in := redactLogin{
+		User:     "bob",
+		Password: "hunter2",
+		Tokens:   []string{"a", "b"},
+		Extra:    map[string]string{"api_token": "t1", "key": "sk-abc", "sk-x": "v", "sk-y": "w"},
+		Inner:    redactInner{Code: 1234},
+		Note:     "sk-123",
+	}
+	other := in
+	other.Password = "x"
+	other.Extra = map[string]string{"api_token": "t2", "key": "sk-abc", "sk-x": "v", "sk-y": "w"}
+	other.Inner.Code = 1
+
+	var dot, html bytes.Buffer
+	cfg.Fdot(&dot, in)
+	cfg.Fhtml(&html, in)
+
+	tests := []struct {
+		line string
+		got  string
+		want string
+	}{
+		{line(), cfg.Sjson(in), "{\n" +
+			" \"User\": \"bob\",\n" +
+			" \"Password\": \"<redacted len=7>\",\n" +
+			" \"Tokens\": \"<redacted len=2>\",\n" +
+			" \"Extra\": {\n" +
+			"  \"api_token\": \"<redacted len=2>\",\n" +
+			"  \"key\": \"<redacted len=6>\",\n" +
+			"  \"<redacted len=4 #2>\": \"v\",\n" +
+			"  \"<redacted len=4 #3>\": \"w\"\n" +
+			" },\n" +
+			" \"Inner\": {\n" +
+			"  \"Code\": \"<redacted>\"\n" +
+			" },\n" +
+			" \"Note\": \"<redacted len=6>\"\n" +
+			"}\n"},
+		{line(), cfg.Syaml(in), "---\n" +
+			"User: bob\n" +
+			"Password: \"<redacted len=7>\"\n" +
+			"Tokens: \"<redacted len=2>\"\n" +
+			"Extra:\n" +
+			"  api_token: \"<redacted len=2>\"\n" +
+			"  key: \"<redacted len=6>\"\n" +
+			"  \"<redacted len=4 #2>\": v\n" +
+			"  \"<redacted len=4 #3>\": w\n" +
+			"Inner:\n" +
+			"  Code: \"<redacted>\"\n" +
+			"Note: \"<redacted len=6>\"\n"},
+		{line(), cfg.Sgo(in), "spew_test.redactLogin{\n" +
+			" User: \"bob\",\n" +
+			" // Password: <redacted len=7>\n" +
+			" // Tokens: <redacted len=2>\n" +
+			" Extra: map[string]string{\n" +
+			"  // \"api_token\": <redacted len=2>\n" +
+			"  \"key\": \"\" /* <redacted len=6> */,\n" +
+			"  \"<redacted len=4 #2>\": \"v\",\n" +
+			"  \"<redacted len=4 #3>\": \"w\",\n" +
+			" },\n" +
+			" Inner: spew_test.redactInner{\n" +
+			"  // Code: <redacted>\n" +
+			" },\n" +
+			" Note: \"\" /* <redacted len=6> */,\n" +
+			"}"},
+		{line(), cfg.Diff(in, other), ".Password: <redacted> -> <redacted>\n" +
+			".Extra[\"api_token\"]: <redacted> -> <redacted>\n" +
+			".Inner.Code: <redacted> -> <redacted>\n"},
+		{line(), dot.String(), "digraph spew {\n" +
+			" node [shape=record];\n" +
+			" n2 [label=\"{<f0> \\\"api_token\\\": \\<redacted len=2\\>|<f1> \\\"key\\\": \\<redacted len=6\\>|<f2> \\<redacted len=4\\>: \\\"v\\\"|<f3> \\<redacted len=4\\>: \\\"w\\\"}\"];\n" +
+			" n3 [label=\"{<f0> Code: \\<redacted\\>}\"];\n" +
+			" n1 [label=\"{<f0> User: \\\"bob\\\"|<f1> Password: \\<redacted len=7\\>|<f2> Tokens: \\<redacted len=2\\>|<f3> Extra: |<f4> Inner: |<f5> Note: \\<redacted len=6\\>}\"];\n" +
+			" n1:f3 -> n2 [style=dashed];\n" +
+			" n1:f4 -> n3 [style=dashed];\n" +
+			"}\n"},
+	}
+
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, test.got, test.want)
+		}
+	}
+
+	for _, want := range []string{
+		`<span class="field">Password</span>: <span class="redacted">&lt;redacted len=7&gt;</span>`,
+		`<span class="key">&#34;api_token&#34;</span>: <span class="redacted">&lt;redacted len=2&gt;</span>`,
+		`<span class="key">&lt;redacted len=4 #2&gt;</span>: <span class="string">&#34;v&#34;</span>`,
+		`<span class="key">&lt;redacted len=4 #3&gt;</span>: <span class="string">&#34;w&#34;</span>`,
+		`<span class="field">Code</span>: <span class="redacted">&lt;redacted&gt;</span>`,
+		`<span class="field">Note</span>: <span class="redacted">&lt;redacted len=6&gt;</span>`,
+	} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("Fhtml:\n got: %s\nwant: %s", html.String(), want)
+		}
+	}
+}
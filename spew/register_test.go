@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// fmtMatrix is formatted on multiple lines by a custom formatter.
+type fmtMatrix [][]int
+
+// fmtPanic has a custom formatter which panics.
+type fmtPanic int
+
+// fmtEvent is used to test custom formatters on struct fields.
+type fmtEvent struct {
+	When time.Time
+	when time.Time
+	M    fmtMatrix
+	P    *time.Time
+}
+
+// TestRegisterFormatter executes all of the tests described by
+// registerTests.
+func TestRegisterFormatter(t *testing.T) {
+	cfg := &spew.Config{Indent: " ", DisablePointerAddresses: true, DisableCapacities: true}
+	cfg.RegisterFormatter(reflect.TypeOf(time.Time{}), func(w io.Writer, v reflect.Value, ctx spew.Context) {
+		io.WriteString(w, v.Interface().(time.Time).Format(time.RFC3339))
+	})
+	cfg.RegisterFormatter(reflect.TypeOf(fmtMatrix{}), func(w io.Writer, v reflect.Value, ctx spew.Context) {
+		if ctx.Inline {
+			fmt.Fprint(w, v.Interface())
+			return
+		}
+		for i, row := range v.Interface().(fmtMatrix) {
+			if i > 0 {
+				io.WriteString(w, "\n"+ctx.Indent)
+			}
+			fmt.Fprint(w, row)
+		}
+	})
+	cfg.RegisterFormatter(reflect.TypeOf(fmtPanic(0)), func(w io.Writer, v reflect.Value, ctx spew.Context) {
+		panic("boom")
+	})
+	cfg.RegisterFormatter(reflect.TypeOf(stringer("")), func(w io.Writer, v reflect.Value, ctx spew.Context) {
+		io.WriteString(w, "custom")
+	})
+
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	event := fmtEvent{When: ts, when: ts, M: fmtMatrix{{1, 2}, {3, 4}}, P: &ts}
+
+	tests := []outputTest{
+		{line(), cfg, "", ts, "(time.Time) 2020-01-02T03:04:05Z\n"},
+		{line(), cfg, "", fmtPanic(1), "(spew_test.fmtPanic) (PANIC=boom)1\n"},
+		{line(), cfg, "", stringer("x"), "(spew_test.stringer) (len=1) custom\n"},
+		{line(), cfg, "%#v", ts, "(time.Time)2020-01-02T03:04:05Z"},
+		{line(), cfg, "%v", fmtPanic(1), "(PANIC=boom)1"},
+		{line(), cfg, "%v", stringer("x"), "custom"},
+	}
+
+	// Formatters can only be called for unexported fields when the unsafe
+	// package is available.
+	if !spew.UnsafeDisabled {
+		tests = append(tests,
+			outputTest{line(), cfg, "", event, "(spew_test.fmtEvent) {\n" +
+				" When: (time.Time) 2020-01-02T03:04:05Z,\n" +
+				" when: (time.Time) 2020-01-02T03:04:05Z,\n" +
+				" M: (spew_test.fmtMatrix) (len=2) [1 2]\n" +
+				" [3 4],\n" +
+				" P: (*time.Time)(2020-01-02T03:04:05Z)\n" +
+				"}\n"},
+			outputTest{line(), cfg, "%v", event, "{2020-01-02T03:04:05Z 2020-01-02T03:04:05Z [[1 2] [3 4]] <*>2020-01-02T03:04:05Z}"},
+		)
+	}
+
+	runOutputTests(t, tests)
+
+	// Copies of a Config do not share changes to their formatters.
+	copied := *cfg
+	copied.RegisterFormatter(reflect.TypeOf(stringer("")), nil)
+	copied.RegisterFormatter(reflect.TypeOf(0), func(w io.Writer, v reflect.Value, ctx spew.Context) {
+		io.WriteString(w, "int")
+	})
+	if s := cfg.Sdump(stringer("x"), 1); s != "(spew_test.stringer) (len=1) custom\n(int) 1\n" {
+		t.Errorf("RegisterFormatter on a copy:\n got: %s", s)
+	}
+	if s := copied.Sdump(stringer("x"), 1); s != "(spew_test.stringer) (len=1) stringer x\n(int) int\n" {
+		t.Errorf("RegisterFormatter on a copy:\n got: %s", s)
+	}
+
+	cfg.RegisterFormatter(reflect.TypeOf(stringer("")), nil)
+	if s := cfg.Sdump(stringer("x")); s != "(spew_test.stringer) (len=1) stringer x\n" {
+		t.Errorf("RegisterFormatter(nil):\n got: %s", s)
+	}
+}
@@ -0,0 +1,296 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// pathStep is a single step of a path.  It selects the struct field named
+// field, or else the element or map entry whose index or key is the text
+// between the brackets, which is * for all of them.  quoted is set for string
+// keys, which are unquoted.
+type pathStep struct {
+	field  string
+	index  string
+	quoted bool
+}
+
+// pathError describes why a path does not select a value.  path is where the
+// problem was found, which is the whole path when it can not be parsed.
+type pathError struct {
+	path string
+	msg  string
+}
+
+func (e *pathError) Error() string {
+	return "spew: " + e.path + ": " + e.msg
+}
+
+// parsePath splits path into its steps, such as .Spec, .Containers, [*] and
+// .Env for .Spec.Containers[*].Env.  An empty path selects the value itself.
+func parsePath(path string) ([]pathStep, error) {
+	var steps []pathStep
+	for s := path; s != ""; {
+		switch s[0] {
+		case '.':
+			n := 1
+			for n < len(s) && s[n] != '.' && s[n] != '[' {
+				n++
+			}
+			if n == 1 {
+				return nil, &pathError{path, "missing field name"}
+			}
+			steps = append(steps, pathStep{field: s[1:n]})
+			s = s[n:]
+
+		case '[':
+			if strings.HasPrefix(s, `["`) {
+				q, err := strconv.QuotedPrefix(s[1:])
+				if err != nil {
+					return nil, &pathError{path, "bad quoted key"}
+				}
+				s = s[1+len(q):]
+				if !strings.HasPrefix(s, "]") {
+					return nil, &pathError{path, "missing ]"}
+				}
+				key, _ := strconv.Unquote(q)
+				steps = append(steps, pathStep{index: key, quoted: true})
+				s = s[1:]
+				break
+			}
+
+			// Keys which are not strings are written the way the Formatter
+			// outputs them, which may include brackets of their own.
+			depth, n := 1, 1
+			for ; n < len(s) && depth > 0; n++ {
+				switch s[n] {
+				case '[':
+					depth++
+				case ']':
+					depth--
+				}
+			}
+			if depth > 0 {
+				return nil, &pathError{path, "missing ]"}
+			}
+			if n == 2 {
+				return nil, &pathError{path, "missing index or key"}
+			}
+			steps = append(steps, pathStep{index: s[1 : n-1]})
+			s = s[n:]
+
+		default:
+			return nil, &pathError{path, fmt.Sprintf("unexpected %q", s[0])}
+		}
+	}
+	return steps, nil
+}
+
+// wildcard returns whether steps contains a [*] step, which can select any
+// number of values.
+func wildcard(steps []pathStep) bool {
+	for _, step := range steps {
+		if step.index == "*" && !step.quoted {
+			return true
+		}
+	}
+	return false
+}
+
+// selector finds the values selected by a path, calling fn with each of them,
+// its full path and the names along it, as matched by the IncludeFields and
+// ExcludeFields options.
+type selector struct {
+	cfg *Config
+	fn  func(path string, names []string, v reflect.Value)
+}
+
+// selectPath calls fn with the full path of every value within v selected by
+// path, in the order Dump would output them.  Unexported fields are skipped
+// when the DisableUnexported option of cfg is set.
+func selectPath(cfg *Config, v reflect.Value, path string, fn func(path string, names []string, v reflect.Value)) error {
+	steps, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	s := &selector{cfg: cfg, fn: fn}
+	return s.match(v, "", []string{""}, steps, false)
+}
+
+// fail returns an error describing why the value at path does not match the
+// next step, unless wild is set, in which case it is simply skipped.
+func (s *selector) fail(wild bool, path, format string, a ...any) error {
+	if wild {
+		return nil
+	}
+	if path == "" {
+		path = "."
+	}
+	return &pathError{path, fmt.Sprintf(format, a...)}
+}
+
+// match calls fn for the values within v, which is at path, selected by
+// steps.  names are the names along path.  Pointers and interfaces along the
+// way are followed.  wild is set once a [*] step has been taken, after which
+// values which do not match are skipped rather than reported.
+func (s *selector) match(v reflect.Value, path string, names []string, steps []pathStep, wild bool) error {
+	if len(steps) == 0 {
+		s.fn(path, names, v)
+		return nil
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return s.fail(wild, path, "nil %s", v.Type())
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return s.fail(wild, path, "nil value")
+	}
+
+	step, rest := steps[0], steps[1:]
+	kind := v.Kind()
+	switch {
+	case step.field != "":
+		if kind != reflect.Struct {
+			return s.fail(wild, path, "%s is not a struct", v.Type())
+		}
+		sf, ok := v.Type().FieldByName(step.field)
+		if !ok || (s.cfg.DisableUnexported && sf.PkgPath != "") {
+			return s.fail(wild, path, "%s has no field %s", v.Type(), step.field)
+		}
+		fv, err := v.FieldByIndexErr(sf.Index)
+		if err != nil {
+			return s.fail(wild, path, "%s", err)
+		}
+		return s.match(fv, path+"."+step.field, appendName(names, step.field), rest, wild)
+
+	case step.index == "*" && !step.quoted:
+		switch kind {
+		case reflect.Array, reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				s.match(v.Index(i), indexPath(path, i), names, rest, true)
+			}
+		case reflect.Map:
+			for _, key := range mapKeys(s.cfg, v) {
+				uk, _ := unpack(key)
+				s.match(v.MapIndex(key), keyPath(s.cfg, path, uk), keyNames(names, uk), rest, true)
+			}
+		default:
+			return s.fail(wild, path, "%s has no elements", v.Type())
+		}
+		return nil
+
+	case kind == reflect.Array || kind == reflect.Slice:
+		i, err := strconv.Atoi(step.index)
+		if err != nil || step.quoted || i < 0 || i >= v.Len() {
+			return s.fail(wild, path, "%s of len %d has no element [%s]", v.Type(), v.Len(), step.index)
+		}
+		return s.match(v.Index(i), indexPath(path, i), names, rest, wild)
+
+	case kind == reflect.Map:
+		want := "[" + step.index + "]"
+		for _, key := range v.MapKeys() {
+			uk, _ := unpack(key)
+			var ok bool
+			if step.quoted {
+				ok = uk.Kind() == reflect.String && uk.String() == step.index
+			} else {
+				ok = uk.Kind() != reflect.String && keyPath(s.cfg, "", uk) == want
+			}
+			if ok {
+				return s.match(v.MapIndex(key), keyPath(s.cfg, path, uk), keyNames(names, uk), rest, wild)
+			}
+		}
+		if step.quoted {
+			want = "[" + strconv.Quote(step.index) + "]"
+		}
+		return s.fail(wild, path, "%s has no key %s", v.Type(), want)
+	}
+	return s.fail(wild, path, "%s has no elements", v.Type())
+}
+
+// appendName returns names followed by name, without modifying names.
+func appendName(names []string, name string) []string {
+	return append(names[:len(names):len(names)], name)
+}
+
+// keyNames returns the names of the map entry with key in the map whose
+// names are names.  Only string keys are names.
+func keyNames(names []string, key reflect.Value) []string {
+	if key.Kind() != reflect.String {
+		return names
+	}
+	return appendName(names, key.String())
+}
+
+// Select returns the value within v selected by path, which is written the
+// way Diff and the Redact option write paths.  Struct fields are selected by
+// name, such as .Spec.Replicas, array and slice elements by index, such as
+// .Items[3], and map entries by key, such as .Labels["app"], where string keys
+// are quoted and other keys are written the way the Formatter outputs them.
+// Pointers and interfaces along the way are followed, and unexported fields
+// can be selected unless the DisableUnexported option of Default is set.
+//
+// The index or key [*] selects every element or map entry, such as in
+// .Spec.Containers[*].Env, in which case a []any holding every value found is
+// returned.  Values which do not have the rest of the path are left out.
+// Otherwise, an error is returned if path does not select a value.
+func Select(v any, path string) (any, error) {
+	steps, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	found := []any{}
+	s := &selector{cfg: &Default}
+	s.fn = func(path string, names []string, v reflect.Value) {
+		if err == nil {
+			var fv any
+			fv, err = valueInterface(path, v)
+			found = append(found, fv)
+		}
+	}
+	if merr := s.match(reflect.ValueOf(v), "", []string{""}, steps, false); merr != nil {
+		return nil, merr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if wildcard(steps) {
+		return found, nil
+	}
+	return found[0], nil
+}
+
+// valueInterface returns the value held by v, which is at path, even when it
+// is an unexported struct field.
+func valueInterface(path string, v reflect.Value) (any, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if !v.CanInterface() && !UnsafeDisabled {
+		v = unsafeReflectValue(v)
+	}
+	if !v.CanInterface() {
+		return nil, &pathError{path, "can not return unexported field"}
+	}
+	return v.Interface(), nil
+}
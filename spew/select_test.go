@@ -0,0 +1,138 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+type selectEnv struct {
+	Name, Value string
+}
+
+type selectContainer struct {
+	Name string
+	Env  []selectEnv
+}
+
+type selectSpec struct {
+	Containers []selectContainer
+	Ports      map[int]string
+	labels     map[string]string
+}
+
+type selectPod struct {
+	Spec *selectSpec
+	Meta any
+}
+
+// selectTest is used to describe a test to be performed against Select.  want
+// is the value selected, formatted with %v, or the error.
+type selectTest struct {
+	line string // use line() to fill this
+	path string
+	want string
+}
+
+// TestSelect executes all of the tests described by selectTests.
+func TestSelect(t *testing.T) {
+	pod := selectPod{
+		Spec: &selectSpec{
+			Containers: []selectContainer{
+				{"a", []selectEnv{{"K", "V"}}},
+				{"b", nil},
+			},
+			Ports:  map[int]string{80: "http"},
+			labels: map[string]string{"app": "web"},
+		},
+		Meta: map[string]any{"owner": selectEnv{"o", "p"}},
+	}
+
+	// Unexported fields can only be returned when the unsafe package is
+	// available.
+	label := "web"
+	if spew.UnsafeDisabled {
+		label = `spew: .Spec.labels["app"]: can not return unexported field`
+	}
+
+	tests := []selectTest{
+		{line(), "", fmt.Sprintf("%v", pod)},
+		{line(), ".Spec.Containers[1].Name", "b"},
+		{line(), ".Spec.Containers[*].Name", "[a b]"},
+		{line(), ".Spec.Containers[*].Env[0].Value", "[V]"},
+		{line(), ".Spec.Containers[*].Nope", "[]"},
+		{line(), ".Spec.Ports[80]", "http"},
+		{line(), `.Spec.labels["app"]`, label},
+		{line(), `.Meta["owner"].Name`, "o"},
+		{line(), ".Spec.Containers[2]", "spew: .Spec.Containers: []spew_test.selectContainer of len 2 has no element [2]"},
+		{line(), ".Spec.Nope", "spew: .Spec: spew_test.selectSpec has no field Nope"},
+		{line(), `.Spec.labels["x"]`, `spew: .Spec.labels: map[string]string has no key ["x"]`},
+		{line(), ".Spec.Ports[81]", "spew: .Spec.Ports: map[int]string has no key [81]"},
+		{line(), ".Spec.Containers[0].Name.X", "spew: .Spec.Containers[0].Name: string is not a struct"},
+		{line(), ".Spec[", "spew: .Spec[: missing ]"},
+		{line(), "Spec", `spew: Spec: unexpected 'S'`},
+		{line(), "..Spec", "spew: ..Spec: missing field name"},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		var s string
+		v, err := spew.Select(pod, test.path)
+		if err != nil {
+			s = err.Error()
+		} else {
+			s = fmt.Sprintf("%v", v)
+		}
+		if s != test.want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, s, test.want)
+		}
+	}
+
+	if _, err := spew.Select((*selectPod)(nil), ".Spec"); err == nil ||
+		err.Error() != "spew: .: nil *spew_test.selectPod" {
+		t.Errorf("Select on nil pointer: got %v", err)
+	}
+}
+
+// TestDumpPaths ensures Dump outputs only the values selected by the Paths
+// option, each preceded by its full path.
+func TestDumpPaths(t *testing.T) {
+	pod := selectPod{Spec: &selectSpec{Containers: []selectContainer{
+		{"a", []selectEnv{{"K", "V"}}},
+		{"b", nil},
+	}}}
+	cfg := spew.Config{Indent: " ", DisableCapacities: true, Paths: []string{
+		".Spec.Containers[*].Env",
+		".Spec.Nope",
+	}}
+
+	s := cfg.Sdump(pod)
+	want := ".Spec.Containers[0].Env: ([]spew_test.selectEnv) (len=1) {\n" +
+		" (spew_test.selectEnv) {\n" +
+		"  Name: (string) (len=1) \"K\",\n" +
+		"  Value: (string) (len=1) \"V\"\n" +
+		" }\n" +
+		"}\n" +
+		".Spec.Containers[1].Env: ([]spew_test.selectEnv) <nil>\n" +
+		".Spec: <spew_test.selectSpec has no field Nope>\n"
+	if s != want {
+		t.Errorf("got: %s\nwant: %s", s, want)
+	}
+}
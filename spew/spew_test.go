@@ -241,7 +241,7 @@ func initSpewTests() {
 				"  (string) (len=3) \"one\": (int) 1,\n" +
 				" },\n" +
 				"}\n"},
-		{line(), cfgNoUnexported, fnConfigSdump, "", tunexp, "(struct { X int; y int }) {\n X: (int) 123,\n}\n"},
+		{line(), cfgNoUnexported, fnConfigSdump, "", tunexp, "(struct { X int; y int }) {\n X: (int) 123\n}\n"},
 		{line(), cfgNoUnexported, fnConfigSprintln, "", tunexp, "{123}\n"},
 		{line(), cfgNoUnexported, fnConfigSprintf, "%v", tunexp, "{123}"},
 		{line(), cfgNoUnexported, fnConfigSprintf, "%#v", tunexp, "(struct { X int; y int }){X:(int)123}"},
@@ -253,10 +253,10 @@ func initSpewTests() {
 		{line(), cfgClean, fnConfigSdump, "", make([]string, 2, 10), "[\n  \"\",\n  \"\"\n]\n"},
 		{line(), cfgClean, fnConfigSprintln, "", make([]int, 2, 10), "[0,0]\n"},
 		{line(), cfgClean, fnConfigSprintf, "%v", make([]int, 2, 10), "[0,0]"},
-		{line(), cfgClean, fnConfigSprintf, "%#v", make([]int, 2, 10), "([]int)[0,0]"},
+		{line(), cfgClean, fnConfigSprintf, "%#v", make([]int, 2, 10), "[0,0]"},
 		{line(), cfgClean, fnConfigSprintln, "", make([]string, 1, 10), "[\"\"]\n"},
 		{line(), cfgClean, fnConfigSprintf, "%v", make([]string, 1, 10), `[""]`},
-		{line(), cfgClean, fnConfigSprintf, "%#v", make([]string, 1, 10), `([]string)[""]`},
+		{line(), cfgClean, fnConfigSprintf, "%#v", make([]string, 1, 10), `[""]`},
 		{line(), cfgClean, fnConfigSdump, "", TestSpew,
 			fmt.Sprintf("spew_test.TestSpew[spew_test.go:%d]\n", funcLine(reflect.ValueOf(TestSpew).Pointer()))},
 		{line(), cfgClean, fnConfigSprintln, "", TestSpew,
@@ -264,13 +264,13 @@ func initSpewTests() {
 		{line(), cfgClean, fnConfigSprintf, "%v", TestSpew,
 			fmt.Sprintf("spew_test.TestSpew[spew_test.go:%d]", funcLine(reflect.ValueOf(TestSpew).Pointer()))},
 		{line(), cfgClean, fnConfigSprintf, "%#v", TestSpew,
-			fmt.Sprintf("(func(*testing.T))spew_test.TestSpew[spew_test.go:%d]", funcLine(reflect.ValueOf(TestSpew).Pointer()))},
+			fmt.Sprintf("spew_test.TestSpew[spew_test.go:%d]", funcLine(reflect.ValueOf(TestSpew).Pointer()))},
 		{line(), cfgClean, fnConfigSprintln, "", tfn,
 			fmt.Sprintf("spew_test.initSpewTests.func1[spew_test.go:%d]\n", funcLine(reflect.ValueOf(tfn).Pointer()))},
 		{line(), cfgClean, fnConfigSprintf, "%v", tfn,
 			fmt.Sprintf("spew_test.initSpewTests.func1[spew_test.go:%d]", funcLine(reflect.ValueOf(tfn).Pointer()))},
 		{line(), cfgClean, fnConfigSprintf, "%#v", tfn,
-			fmt.Sprintf("(func())spew_test.initSpewTests.func1[spew_test.go:%d]", funcLine(reflect.ValueOf(tfn).Pointer()))},
+			fmt.Sprintf("spew_test.initSpewTests.func1[spew_test.go:%d]", funcLine(reflect.ValueOf(tfn).Pointer()))},
 	}
 }
 
@@ -399,3 +399,41 @@ func TestSpew(t *testing.T) {
 		}
 	}
 }
+
+// outputTest is used to describe a test to be performed against the output of
+// a Config.  The Formatter is tested with format, and Sdump is used if it is
+// empty.
+type outputTest struct {
+	line   string // use line() to fill this
+	cfg    *spew.Config
+	format string
+	in     any
+	want   string
+}
+
+// runOutputTests executes all of the passed tests.
+func runOutputTests(t *testing.T, tests []outputTest) {
+	t.Helper()
+	runOutputTestsFunc(t, tests, nil)
+}
+
+// runOutputTestsFunc executes all of the passed tests, passing their output
+// through clean, if it is not nil, before it is compared.
+func runOutputTestsFunc(t *testing.T, tests []outputTest, clean func(string) string) {
+	t.Helper()
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		var s string
+		if test.format == "" {
+			s = test.cfg.Sdump(test.in)
+		} else {
+			s = test.cfg.Sprintf(test.format, test.in)
+		}
+		if clean != nil {
+			s = clean(s)
+		}
+		if s != test.want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, s, test.want)
+		}
+	}
+}
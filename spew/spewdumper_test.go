@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// ringBuffer implements SpewDumper with multi-line output, as well as the
+// Stringer interface, which SpewDumper takes precedence over.
+type ringBuffer struct {
+	items []int
+}
+
+func (r ringBuffer) SpewDump(w io.Writer, ctx spew.Context) {
+	if ctx.Inline {
+		fmt.Fprintf(w, "ring%v", r.items)
+		return
+	}
+	io.WriteString(w, "ring {\n")
+	for _, item := range r.items {
+		fmt.Fprintf(w, "%s%s%d,\n", ctx.Indent, ctx.Config.Indent, item)
+	}
+	io.WriteString(w, ctx.Indent+"}")
+}
+
+func (r ringBuffer) String() string {
+	return "stringer ring"
+}
+
+// pdumper implements SpewDumper with a pointer receiver.
+type pdumper int
+
+func (p *pdumper) SpewDump(w io.Writer, ctx spew.Context) {
+	fmt.Fprintf(w, "pdumper %d at depth %d", int(*p), ctx.Depth)
+}
+
+// dumperHolder is used to test that SpewDumper output nests correctly.
+type dumperHolder struct {
+	R ringBuffer
+	P pdumper
+}
+
+// TestSpewDumper executes all of the tests described by spewDumperTests.
+func TestSpewDumper(t *testing.T) {
+	cfg := &spew.Config{Indent: " ", DisableCapacities: true}
+	cfgNoMethods := &spew.Config{Indent: " ", DisableCapacities: true, DisableMethods: true}
+	cfgContinue := &spew.Config{Indent: " ", DisableCapacities: true, ContinueOnMethod: true}
+
+	ring := ringBuffer{items: []int{1, 2}}
+	holder := dumperHolder{R: ring, P: 3}
+
+	// The pointer receiver method can only be called on the field, which
+	// isn't addressable, when the unsafe package is available.
+	pdump := "pdumper 3 at depth 1"
+	if spew.UnsafeDisabled {
+		pdump = "3"
+	}
+
+	tests := []outputTest{
+		{line(), cfg, "", ring, "(spew_test.ringBuffer) ring {\n 1,\n 2,\n}\n"},
+		{line(), cfg, "", holder, "(spew_test.dumperHolder) {\n" +
+			" R: (spew_test.ringBuffer) ring {\n" +
+			"  1,\n" +
+			"  2,\n" +
+			" },\n" +
+			" P: (spew_test.pdumper) " + pdump + "\n" +
+			"}\n"},
+		{line(), cfgContinue, "", ring, "(spew_test.ringBuffer) ring {\n 1,\n 2,\n}\n"},
+		{line(), cfgNoMethods, "", ring, "(spew_test.ringBuffer) {\n" +
+			" items: ([]int) (len=2) {\n" +
+			"  (int) 1,\n" +
+			"  (int) 2\n" +
+			" }\n" +
+			"}\n"},
+		{line(), cfg, "%v", holder, "{ring[1 2] " + pdump + "}"},
+		{line(), cfg, "%+v", holder, "{R:ring[1 2] P:" + pdump + "}"},
+		{line(), cfgNoMethods, "%v", ring, "{[1 2]}"},
+	}
+
+	runOutputTests(t, tests)
+
+	if s := spew.Sdump(ring); s != "(spew_test.ringBuffer) ring {\n 1,\n 2,\n}\n" {
+		t.Errorf("spew.Sdump:\n got: %s", s)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// taggedStruct has fields with each of the spew struct tag options.
+type taggedStruct struct {
+	Name     string
+	Skip     int    `spew:"-"`
+	Password string `spew:"redact"`
+	Flags    uint16 `spew:"hex"`
+	Offsets  []int  `spew:"hex"`
+	Note     string `spew:"omitempty"`
+	Last     int
+}
+
+// TestStructTags executes all of the tests described by tagTests.
+func TestStructTags(t *testing.T) {
+	cfg := &spew.Config{Indent: " ", DisableCapacities: true}
+
+	in := taggedStruct{Name: "a", Skip: 1, Password: "secret", Flags: 0x1f,
+		Offsets: []int{-1, 255}, Last: 2}
+	withNote := in
+	withNote.Note = "n"
+
+	tests := []outputTest{
+		{line(), cfg, "", in, "(spew_test.taggedStruct) {\n" +
+			" Name: (string) (len=1) \"a\",\n" +
+			" Password: (string) <redacted>,\n" +
+			" Flags: (uint16) 0x1f,\n" +
+			" Offsets: ([]int) (len=2) {\n" +
+			"  (int) -0x1,\n" +
+			"  (int) 0xff\n" +
+			" },\n" +
+			" Last: (int) 2\n" +
+			"}\n"},
+		{line(), cfg, "", withNote, "(spew_test.taggedStruct) {\n" +
+			" Name: (string) (len=1) \"a\",\n" +
+			" Password: (string) <redacted>,\n" +
+			" Flags: (uint16) 0x1f,\n" +
+			" Offsets: ([]int) (len=2) {\n" +
+			"  (int) -0x1,\n" +
+			"  (int) 0xff\n" +
+			" },\n" +
+			" Note: (string) (len=1) \"n\",\n" +
+			" Last: (int) 2\n" +
+			"}\n"},
+		{line(), cfg, "%v", in, "{a <redacted> 0x1f [-0x1 0xff] 2}"},
+		{line(), cfg, "%+v", withNote, "{Name:a Password:<redacted> Flags:0x1f Offsets:[-0x1 0xff] Note:n Last:2}"},
+		{line(), cfg, "%#v", in, "(spew_test.taggedStruct){Name:(string)a Password:(string)<redacted> " +
+			"Flags:(uint16)0x1f Offsets:([]int)[-0x1 0xff] Last:(int)2}"},
+	}
+
+	runOutputTests(t, tests)
+
+	if s := spew.Sprint(in); s != "{a <redacted> 0x1f [-0x1 0xff] 2}" {
+		t.Errorf("spew.Sprint:\n got: %s", s)
+	}
+}
+
+// TestStructTagsFormats ensures the spew struct tags are honored by the output
+// formats which don't share the Dump walker.
+func TestStructTagsFormats(t *testing.T) {
+	cfg := &spew.Config{Indent: " ", DisableTypes: true, DisableLengths: true,
+		DisablePointerAddresses: true}
+
+	in := taggedStruct{Name: "a", Skip: 1, Password: "secret", Flags: 0x1f,
+		Offsets: []int{-1, 255}, Last: 2}
+	other := in
+	other.Skip = 3
+	other.Password = "other"
+
+	var dot, html bytes.Buffer
+	cfg.Fdot(&dot, in)
+	cfg.Fhtml(&html, in)
+
+	tests := []struct {
+		line string
+		got  string
+		want string
+	}{
+		{line(), cfg.Sjson(in), "{\n" +
+			" \"Name\": \"a\",\n" +
+			" \"Password\": \"<redacted>\",\n" +
+			" \"Flags\": 31,\n" +
+			" \"Offsets\": [\n" +
+			"  -1,\n" +
+			"  255\n" +
+			" ],\n" +
+			" \"Last\": 2\n" +
+			"}\n"},
+		{line(), cfg.Syaml(in), "---\n" +
+			"Name: a\n" +
+			"Password: \"<redacted>\"\n" +
+			"Flags: 0x1f\n" +
+			"Offsets:\n" +
+			"  - -0x1\n" +
+			"  - 0xff\n" +
+			"Last: 2\n"},
+		{line(), cfg.Sgo(in), "spew_test.taggedStruct{\n" +
+			" Name: \"a\",\n" +
+			" // Password: <redacted>\n" +
+			" Flags: 0x1f,\n" +
+			" Offsets: []int{\n" +
+			"  -0x1,\n" +
+			"  0xff,\n" +
+			" },\n" +
+			" Last: 2,\n" +
+			"}"},
+		{line(), cfg.Diff(in, other), ".Password: <redacted> -> <redacted>\n"},
+		{line(), cfg.Diff(in, in), ""},
+		{line(), dot.String(), "digraph spew {\n" +
+			" node [shape=record];\n" +
+			" n2 [label=\"{<f0> -1|<f1> 255}\"];\n" +
+			" n1 [label=\"{<f0> Name: \\\"a\\\"|<f1> Password: \\<redacted\\>|<f2> Flags: 31|<f3> Offsets: |<f4> Last: 2}\"];\n" +
+			" n1:f3 -> n2 [style=dashed];\n" +
+			"}\n"},
+	}
+
+	for _, test := range tests {
+		if test.got != test.want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, test.got, test.want)
+		}
+	}
+
+	for _, want := range []string{
+		`<span class="field">Password</span>: <span class="redacted">&lt;redacted&gt;</span>`,
+		`<span class="field">Flags</span>: <span class="value">0x1f</span>`,
+	} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("Fhtml:\n got: %s\nwant: %s", html.String(), want)
+		}
+	}
+	if strings.Contains(html.String(), "Skip") || strings.Contains(html.String(), "secret") {
+		t.Errorf("Fhtml shows a skipped or redacted field:\n got: %s", html.String())
+	}
+}
@@ -0,0 +1,512 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// builtinTypes maps the names of the predeclared types, as shown by Dump, to
+// their types.  It is used to find the concrete type of values held in
+// interfaces.
+var builtinTypes = map[string]reflect.Type{
+	"bool":         reflect.TypeOf(false),
+	"int":          reflect.TypeOf(int(0)),
+	"int8":         reflect.TypeOf(int8(0)),
+	"int16":        reflect.TypeOf(int16(0)),
+	"int32":        reflect.TypeOf(int32(0)),
+	"int64":        reflect.TypeOf(int64(0)),
+	"uint":         reflect.TypeOf(uint(0)),
+	"uint8":        reflect.TypeOf(uint8(0)),
+	"uint16":       reflect.TypeOf(uint16(0)),
+	"uint32":       reflect.TypeOf(uint32(0)),
+	"uint64":       reflect.TypeOf(uint64(0)),
+	"uintptr":      reflect.TypeOf(uintptr(0)),
+	"float32":      reflect.TypeOf(float32(0)),
+	"float64":      reflect.TypeOf(float64(0)),
+	"complex64":    reflect.TypeOf(complex64(0)),
+	"complex128":   reflect.TypeOf(complex128(0)),
+	"string":       reflect.TypeOf(""),
+	"interface {}": reflect.TypeOf((*any)(nil)).Elem(),
+}
+
+// typeFromName returns the type named by name, as shown by Dump, if it is
+// built only from predeclared types.
+func typeFromName(name string) (reflect.Type, bool) {
+	if t, ok := builtinTypes[name]; ok {
+		return t, true
+	}
+	switch {
+	case strings.HasPrefix(name, "*"):
+		if t, ok := typeFromName(name[1:]); ok {
+			return reflect.PtrTo(t), true
+		}
+
+	case strings.HasPrefix(name, "[]"):
+		if t, ok := typeFromName(name[2:]); ok {
+			return reflect.SliceOf(t), true
+		}
+
+	case strings.HasPrefix(name, "["):
+		end := strings.IndexByte(name, ']')
+		if end < 0 {
+			break
+		}
+		n, err := strconv.Atoi(name[1:end])
+		if err != nil || n < 0 {
+			break
+		}
+		if t, ok := typeFromName(name[end+1:]); ok {
+			return reflect.ArrayOf(n, t), true
+		}
+
+	case strings.HasPrefix(name, "map["):
+		// Find the bracket which closes the key type.
+		depth := 0
+		for i := 3; i < len(name); i++ {
+			switch name[i] {
+			case '[':
+				depth++
+				continue
+			case ']':
+				depth--
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+			k, ok := typeFromName(name[4:i])
+			if !ok || !k.Comparable() {
+				break
+			}
+			if e, ok := typeFromName(name[i+1:]); ok {
+				return reflect.MapOf(k, e), true
+			}
+			break
+		}
+	}
+	return nil, false
+}
+
+// inferType returns the type to use for a value held in an interface when
+// its type was not shown, or is not built only from predeclared types.
+func inferType(n *Node) reflect.Type {
+	switch n.Kind {
+	case ScalarNode:
+		if n.Value == "true" || n.Value == "false" {
+			return builtinTypes["bool"]
+		}
+		if _, err := strconv.ParseInt(n.Value, 10, 0); err == nil {
+			return builtinTypes["int"]
+		}
+		if _, err := strconv.ParseFloat(n.Value, 64); err == nil {
+			return builtinTypes["float64"]
+		}
+		if _, err := strconv.ParseComplex(n.Value, 128); err == nil {
+			return builtinTypes["complex128"]
+		}
+
+	case BytesNode:
+		return reflect.SliceOf(builtinTypes["uint8"])
+
+	case ListNode:
+		return reflect.SliceOf(builtinTypes["interface {}"])
+
+	case MapNode, StructNode:
+		for _, c := range n.Children {
+			if c.Key != nil && c.Key.Kind != StringNode {
+				return reflect.MapOf(builtinTypes["interface {}"], builtinTypes["interface {}"])
+			}
+		}
+		return reflect.MapOf(builtinTypes["string"], builtinTypes["interface {}"])
+	}
+	return builtinTypes["string"]
+}
+
+// undumpState contains information about the state of an undump operation.
+type undumpState struct {
+	// addrs maps the pointer addresses shown in the dump to the pointers
+	// which were allocated for them, so that shared pointers are restored.
+	// pointers holds the pointers which are being filled in, so that
+	// circular references can be restored when addresses were not shown.
+	addrs    map[string]reflect.Value
+	pointers []reflect.Value
+}
+
+// errorf returns an error describing a problem with the value at path.
+func (u *undumpState) errorf(path, format string, a ...any) error {
+	if path == "" {
+		path = "."
+	}
+	return fmt.Errorf("spew: %s: %s", path, fmt.Sprintf(format, a...))
+}
+
+// mismatch returns an error describing a node which can't be stored in v.
+func (u *undumpState) mismatch(path string, v reflect.Value, n *Node) error {
+	if n.Kind == ScalarNode {
+		return u.errorf(path, "cannot undump %q into %s", n.Value, v.Type())
+	}
+	return u.errorf(path, "cannot undump %s into %s", n.Kind, v.Type())
+}
+
+// isEmptyBlock returns whether n is a collection without entries, which can be
+// stored in any kind of collection since Dump output doesn't always show
+// which kind it is.
+func isEmptyBlock(n *Node) bool {
+	return (n.Kind == ListNode || n.Kind == MapNode || n.Kind == StructNode) &&
+		len(n.Children) == 0
+}
+
+// deref returns n as seen through one level of pointer indirection.
+func deref(n *Node) *Node {
+	c := *n
+	c.Type = strings.TrimPrefix(n.Type, "*")
+	if len(n.Addrs) > 0 {
+		c.Addrs = n.Addrs[1:]
+	}
+	return &c
+}
+
+// undumpPtr handles storing a node in a pointer by allocating a new value for
+// it to point to, unless the same pointer was already allocated.
+func (u *undumpState) undumpPtr(path string, v reflect.Value, n *Node) error {
+	if len(n.Addrs) > 0 {
+		if p, ok := u.addrs[n.Addrs[0]]; ok && p.Type() == v.Type() {
+			v.Set(p)
+			return nil
+		}
+	} else {
+		switch n.Kind {
+		case NilNode:
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+
+		case CircularNode:
+			for i := len(u.pointers) - 1; i >= 0; i-- {
+				if u.pointers[i].Type() == v.Type() {
+					v.Set(u.pointers[i])
+					return nil
+				}
+			}
+			return u.errorf(path, "circular reference to unknown %s", v.Type())
+		}
+	}
+
+	p := reflect.New(v.Type().Elem())
+	v.Set(p)
+	if len(n.Addrs) > 0 {
+		u.addrs[n.Addrs[0]] = p
+	}
+	u.pointers = append(u.pointers, p)
+	defer func() { u.pointers = u.pointers[:len(u.pointers)-1] }()
+	return u.undump(path, p.Elem(), deref(n))
+}
+
+// undumpInterface handles storing a node in an interface by finding the
+// concrete type to store from the type shown, or from the node itself if it
+// wasn't shown.
+func (u *undumpState) undumpInterface(path string, v reflect.Value, n *Node) error {
+	if len(n.Addrs) > 0 {
+		if p, ok := u.addrs[n.Addrs[0]]; ok && p.Type().AssignableTo(v.Type()) {
+			v.Set(p)
+			return nil
+		}
+	}
+	switch n.Kind {
+	case NilNode:
+		if len(n.Addrs) == 0 {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+
+	case CircularNode:
+		for i := len(u.pointers) - 1; i >= 0; i-- {
+			p := u.pointers[i]
+			if p.Type().String() == n.Type && p.Type().AssignableTo(v.Type()) {
+				v.Set(p)
+				return nil
+			}
+		}
+		return u.errorf(path, "circular reference to unknown %s", n.Type)
+	}
+
+	t, ok := typeFromName(n.Type)
+	if !ok {
+		if v.NumMethod() > 0 {
+			return u.errorf(path, "cannot determine concrete type for %s", v.Type())
+		}
+		t = inferType(n)
+	}
+	if !t.AssignableTo(v.Type()) {
+		return u.errorf(path, "%s does not implement %s", t, v.Type())
+	}
+	e := reflect.New(t).Elem()
+	if err := u.undump(path, e, n); err != nil {
+		return err
+	}
+	v.Set(e)
+	return nil
+}
+
+// undumpList handles storing a node in an array or slice.  Hexdumps are
+// stored as bytes.
+func (u *undumpState) undumpList(path string, v reflect.Value, n *Node) error {
+	numEntries := len(n.Children)
+	if n.Kind == BytesNode {
+		numEntries = len(n.Bytes)
+	} else if n.Kind != ListNode && !isEmptyBlock(n) {
+		return u.mismatch(path, v, n)
+	}
+
+	if v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), numEntries, numEntries))
+	} else if numEntries > v.Len() {
+		return u.errorf(path, "%d elements do not fit in %s", numEntries, v.Type())
+	}
+
+	if n.Kind == BytesNode {
+		vt := v.Type().Elem()
+		if !uint8Type.ConvertibleTo(vt) {
+			return u.mismatch(path, v, n)
+		}
+		for i, b := range n.Bytes {
+			v.Index(i).Set(reflect.ValueOf(b).Convert(vt))
+		}
+		return nil
+	}
+	for i, c := range n.Children {
+		if err := u.undump(path+"["+strconv.Itoa(i)+"]", v.Index(i), c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// undumpMap handles storing a node in a map.  Structs are stored with their
+// field names as keys.
+func (u *undumpState) undumpMap(path string, v reflect.Value, n *Node) error {
+	if n.Kind != MapNode && n.Kind != StructNode && !isEmptyBlock(n) {
+		return u.mismatch(path, v, n)
+	}
+
+	vt := v.Type()
+	m := reflect.MakeMapWithSize(vt, len(n.Children))
+	for _, c := range n.Children {
+		keyNode := c.Key
+		if keyNode == nil {
+			keyNode = &Node{Kind: StringNode, Value: c.Name}
+		}
+		keyText := keyNode.Value
+		if keyNode.Kind == StringNode {
+			keyText = strconv.Quote(keyText)
+		}
+		entryPath := path + "[" + keyText + "]"
+
+		key := reflect.New(vt.Key()).Elem()
+		if err := u.undump(entryPath, key, keyNode); err != nil {
+			return err
+		}
+		val := reflect.New(vt.Elem()).Elem()
+		if err := u.undump(entryPath, val, c); err != nil {
+			return err
+		}
+		m.SetMapIndex(key, val)
+	}
+	v.Set(m)
+	return nil
+}
+
+// undumpStruct handles storing a node in a struct field by field.
+func (u *undumpState) undumpStruct(path string, v reflect.Value, n *Node) error {
+	if n.Kind != StructNode && !isEmptyBlock(n) {
+		return u.mismatch(path, v, n)
+	}
+
+	vt := v.Type()
+	numFields := v.NumField()
+	for _, c := range n.Children {
+		i := 0
+		for i < numFields && vt.Field(i).Name != c.Name {
+			i++
+		}
+		if i == numFields {
+			return u.errorf(path, "no field %s in %s", c.Name, vt)
+		}
+		if err := u.undump(path+"."+c.Name, v.Field(i), c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// undump is the main workhorse for storing a node in a value.  It uses the
+// kind of v to figure out how to interpret the node.
+func (u *undumpState) undump(path string, v reflect.Value, n *Node) error {
+	// Unexported fields can only be set by bypassing the usual safety
+	// restrictions.
+	if !v.CanSet() {
+		v = unsafeReflectValue(v)
+		if !v.CanSet() {
+			return u.errorf(path, "cannot set unexported field")
+		}
+	}
+
+	kind := v.Kind()
+	switch kind {
+	case reflect.Ptr:
+		return u.undumpPtr(path, v, n)
+
+	case reflect.Interface:
+		return u.undumpInterface(path, v, n)
+	}
+
+	switch n.Kind {
+	case NilNode, InvalidNode:
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+
+	case CircularNode:
+		return u.mismatch(path, v, n)
+	}
+
+	// Values which were not shown due to the MaxDepth option are left as
+	// they are.
+	if len(n.Children) == 1 && n.Children[0].Kind == MaxDepthNode {
+		return nil
+	}
+
+	switch kind {
+	case reflect.Slice, reflect.Array:
+		return u.undumpList(path, v, n)
+
+	case reflect.Map:
+		return u.undumpMap(path, v, n)
+
+	case reflect.Struct:
+		return u.undumpStruct(path, v, n)
+
+	case reflect.String:
+		if n.Kind != StringNode {
+			return u.mismatch(path, v, n)
+		}
+		v.SetString(n.Value)
+		return nil
+
+	// The addresses of channels, functions and unsafe pointers are
+	// meaningless outside of the process which dumped them, so they are
+	// left as they are.
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return nil
+	}
+
+	if n.Kind != ScalarNode {
+		return u.mismatch(path, v, n)
+	}
+	var err error
+	switch kind {
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(n.Value); err == nil {
+			v.SetBool(b)
+		}
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		var i int64
+		if i, err = strconv.ParseInt(n.Value, 10, v.Type().Bits()); err == nil {
+			v.SetInt(i)
+		}
+
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		var i uint64
+		if i, err = strconv.ParseUint(n.Value, 10, v.Type().Bits()); err == nil {
+			v.SetUint(i)
+		}
+
+	case reflect.Uintptr:
+		var i uint64
+		if i, err = strconv.ParseUint(n.Value, 0, v.Type().Bits()); err == nil {
+			v.SetUint(i)
+		}
+
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(n.Value, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+
+	case reflect.Complex64, reflect.Complex128:
+		var c complex128
+		if c, err = strconv.ParseComplex(n.Value, v.Type().Bits()); err == nil {
+			v.SetComplex(c)
+		}
+
+	default:
+		err = errors.New("unsupported kind")
+	}
+	if err != nil {
+		return u.mismatch(path, v, n)
+	}
+	return nil
+}
+
+// Undump parses text, which holds the output of Dump for a single value, and
+// stores the result in the value pointed to by out.  It is meant for
+// replaying state which was captured with Dump, such as with CleanConfig,
+// without writing fixtures by hand.  See ParseDump for details on how the
+// text is parsed.
+//
+// The value is filled in the same way Dump walks it.  Pointers are allocated
+// as needed, and unexported fields are set by bypassing the usual safety
+// restrictions when the unsafe package is available.  Pointers which were
+// shown with the same address point to the same value.  Circular references
+// are restored even when addresses were not shown, but then point to the
+// nearest enclosing pointer of the same type, which may not be the one which
+// was dumped.  Values held in interfaces are stored with their shown type if
+// it is built only from predeclared types, and otherwise with a type inferred
+// from the text, such as []any for lists and map[string]any for structs.
+//
+// The addresses of channels, functions and unsafe pointers are meaningless
+// outside of the process which dumped them, so they are left as they are, as
+// are values which were not shown due to the MaxDepth option.
+func Undump(text string, out any) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("spew: Undump requires a non-nil pointer, got %T", out)
+	}
+
+	n, err := ParseDump(strings.NewReader(text))
+	if err != nil {
+		return err
+	}
+	u := undumpState{addrs: make(map[string]reflect.Value)}
+
+	// A dumped pointer is stored in the value out points to rather than a
+	// new one, so out takes its place in any circular references.
+	if strings.HasPrefix(n.Type, "*") && v.Elem().Kind() != reflect.Ptr {
+		if len(n.Addrs) > 0 {
+			u.addrs[n.Addrs[0]] = v
+		}
+		u.pointers = append(u.pointers, v)
+		n = deref(n)
+	}
+	return u.undump("", v.Elem(), n)
+}
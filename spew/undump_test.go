@@ -0,0 +1,166 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// undumpState is used to test replaying nested state, including unexported
+// fields, interfaces and shared pointers.
+type undumpState struct {
+	Name    string
+	Count   *int
+	Ratio   float32
+	Tags    []string
+	Attrs   map[string]any
+	Data    []byte
+	Parent  *undumpState
+	Child   *undumpState
+	Alias   *undumpState
+	Fixed   [2]uint16
+	Values  map[int]complex128
+	secret  string
+	flagged bool
+}
+
+// TestUndump ensures values dumped with various configurations can be
+// restored.
+func TestUndump(t *testing.T) {
+	count := 3
+	in := &undumpState{
+		Name:  "root",
+		Count: &count,
+		Ratio: 0.5,
+		Tags:  []string{"a", "b\n"},
+		Attrs: map[string]any{
+			"int":    1,
+			"float":  1.5,
+			"string": "s",
+			"list":   []any{"x", 2},
+			"nil":    nil,
+		},
+		Data:    []byte("hello world, hello world"),
+		Fixed:   [2]uint16{1, 2},
+		Values:  map[int]complex128{1: complex(1, -2)},
+		secret:  "hidden",
+		flagged: true,
+	}
+	in.Child = &undumpState{Name: "child", Parent: in}
+	in.Alias = in.Child
+
+	configs := []struct {
+		line string // use line() to fill this
+		cfg  *spew.Config
+	}{
+		{line(), &spew.CleanConfig},
+		{line(), &spew.Default},
+		{line(), &spew.Config{Indent: "\t", SortKeys: true, TrailingCommas: true}},
+		{line(), &spew.Config{Indent: " ", PointerOrdinals: true}},
+	}
+
+	// Unexported fields can only be restored with the unsafe package, so
+	// they are left out of the dumps without it.
+	restorable := func(cfg *spew.Config) *spew.Config {
+		if !spew.UnsafeDisabled {
+			return cfg
+		}
+		c := *cfg
+		c.DisableUnexported = true
+		return &c
+	}
+
+	for _, c := range configs {
+		var out undumpState
+		dump := restorable(c.cfg).Sdump(in)
+		if err := spew.Undump(dump, &out); err != nil {
+			t.Errorf("testcase on line %s: %v\n%s", c.line, err, dump)
+			continue
+		}
+		if out.Child == nil || out.Child.Parent == nil {
+			t.Errorf("testcase on line %s: circular reference not restored", c.line)
+			continue
+		}
+
+		// Compare the values without their circular references.  The alias
+		// is only the same pointer as the child when addresses were shown.
+		out.Child.Parent = nil
+		if out.Alias != nil {
+			out.Alias.Parent = nil
+		}
+		child := *in.Child
+		child.Parent = nil
+		want := *in
+		want.Child = &child
+		want.Alias = &child
+		if spew.UnsafeDisabled {
+			want.secret, want.flagged = "", false
+		}
+		if !reflect.DeepEqual(&out, &want) {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", c.line,
+				spew.Sdump(&out), spew.Sdump(&want))
+		}
+	}
+
+	// Pointers shown with the same address point to the same value.
+	var out undumpState
+	if err := spew.Undump(restorable(&spew.Default).Sdump(in), &out); err != nil {
+		t.Fatalf("Undump: %v", err)
+	}
+	if out.Alias != out.Child || out.Child.Parent != &out {
+		t.Errorf("Undump: shared pointers not restored")
+	}
+}
+
+// TestUndumpErrors ensures Undump reports values which can't be restored.
+func TestUndumpErrors(t *testing.T) {
+	var i int
+	var s undumpState
+	var m map[string]int
+	var a [1]int
+	var e error
+	tests := []struct {
+		line string // use line() to fill this
+		in   string
+		out  any
+		want string
+	}{
+		{line(), "(int) 5", i, "spew: Undump requires a non-nil pointer, got int"},
+		{line(), "(int) 5", (*int)(nil), "spew: Undump requires a non-nil pointer, got *int"},
+		{line(), "(int)5", &i, "spew: line 1: expected space after type (int)"},
+		{line(), `(string) "a"`, &i, "spew: .: cannot undump StringNode into int"},
+		{line(), "(int8) 300", new(int8), `spew: .: cannot undump "300" into int8`},
+		{line(), "{\n Name: 5\n}", &s, `spew: .Name: cannot undump "5" into string`},
+		{line(), "{\n Bogus: 5\n}", &s, "spew: .: no field Bogus in spew_test.undumpState"},
+		{line(), "{\n \"a\": x\n}", &m, `spew: ["a"]: cannot undump "x" into int`},
+		{line(), "[\n 1,\n 2\n]", &a, "spew: .: 2 elements do not fit in [1]int"},
+		{line(), "(spew_test.customError) error: 1", &e,
+			"spew: .: cannot determine concrete type for error"},
+		{line(), "{\n Parent: (*spew_test.undumpState)(<already shown>)\n}", &s,
+			"spew: .Parent: circular reference to unknown *spew_test.undumpState"},
+	}
+
+	for _, test := range tests {
+		err := spew.Undump(test.in, test.out)
+		if err == nil || err.Error() != test.want {
+			t.Errorf("testcase on line %s:\n got: %v\nwant: %s", test.line, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"io"
+	"reflect"
+)
+
+// Visitor is notified of the values found by Walk.  Every value is reported to
+// exactly one of the Enter and Leave pairs, Pointer or Leaf, along with its
+// path, such as .Items[3].Name, which is empty for the value passed to Walk.
+//
+// Values which are not exported, such as unexported struct fields, are passed
+// such that Interface can be called on them, unless the unsafe package is not
+// available.  Embed BaseVisitor to implement only some of the methods.
+type Visitor interface {
+	// EnterStruct and LeaveStruct are called before and after the fields of
+	// the struct v are walked.
+	EnterStruct(path string, v reflect.Value)
+	LeaveStruct(path string, v reflect.Value)
+
+	// Field is called before the value v of the struct field name is
+	// walked.  v is invalid if it is redacted, and is not walked.
+	Field(path string, name string, v reflect.Value)
+
+	// EnterMap and LeaveMap are called before and after the entries of the
+	// map v are walked.
+	EnterMap(path string, v reflect.Value)
+	LeaveMap(path string, v reflect.Value)
+
+	// MapEntry is called before the value v for key is walked.  Keys are not
+	// walked themselves.  v is invalid if it is redacted, and is not
+	// walked.
+	MapEntry(path string, key, v reflect.Value)
+
+	// EnterSlice and LeaveSlice are called before and after the elements of
+	// the array or slice v are walked.
+	EnterSlice(path string, v reflect.Value)
+	LeaveSlice(path string, v reflect.Value)
+
+	// Element is called before the element v at index i of an array or
+	// slice is walked.  v is invalid if it is redacted, and is not walked.
+	Element(path string, i int, v reflect.Value)
+
+	// Pointer is called for a chain of pointers.  The value at the end of it
+	// is walked next, with the same path, unless it is nil, circular or
+	// shared.
+	Pointer(path string, p Pointer)
+
+	// Leaf is called for all other values, including nil interfaces, maps
+	// and slices.  It is passed an invalid reflect.Value for a nil value
+	// passed to Walk.
+	Leaf(path string, v reflect.Value)
+}
+
+// Pointer describes a chain of pointers found by Walk, such as a **T, where
+// pointers to interfaces are unpacked along the way.
+type Pointer struct {
+	// Addrs are the addresses of the pointers in the chain.
+	Addrs []uintptr
+
+	// Elem is the value at the end of the chain.  It is the last pointer in
+	// the chain when Circular or Shared is set, and invalid when Nil is set.
+	Elem reflect.Value
+
+	// Nil is set when the chain ends in a nil pointer or interface.
+	Nil bool
+
+	// Circular is set when the last pointer in the chain points to a value
+	// which contains it, which is not walked again.
+	Circular bool
+
+	// Shared is set, with the DedupeShared option, when the last pointer in
+	// the chain was already followed at SharedPath, and is not walked again.
+	Shared     bool
+	SharedPath string
+}
+
+// BaseVisitor implements Visitor with methods which do nothing.  It is meant
+// to be embedded in Visitors which only need some of the methods.
+type BaseVisitor struct{}
+
+func (BaseVisitor) EnterStruct(path string, v reflect.Value)    {}
+func (BaseVisitor) LeaveStruct(path string, v reflect.Value)    {}
+func (BaseVisitor) Field(path, name string, v reflect.Value)    {}
+func (BaseVisitor) EnterMap(path string, v reflect.Value)       {}
+func (BaseVisitor) LeaveMap(path string, v reflect.Value)       {}
+func (BaseVisitor) MapEntry(path string, key, v reflect.Value)  {}
+func (BaseVisitor) EnterSlice(path string, v reflect.Value)     {}
+func (BaseVisitor) LeaveSlice(path string, v reflect.Value)     {}
+func (BaseVisitor) Element(path string, i int, v reflect.Value) {}
+func (BaseVisitor) Pointer(path string, p Pointer)              {}
+func (BaseVisitor) Leaf(path string, v reflect.Value)           {}
+
+// Walk traverses v the same way Dump does and reports what it finds to
+// visitor.  Pointers are followed, with circular data structures detected and
+// handled properly, and unexported fields are walked unless the
+// DisableUnexported option of cfg is set.  The options of cfg which choose the
+// fields, elements and depth to output, such as SortKeys, OmitNilFields,
+// OmitZeroFields, Redact, MaxDepth, MaxElements and DedupeShared, choose what
+// is walked.  Fields, map entries and elements left out by these options are
+// not reported, and neither are redacted values.  Custom formatters and
+// methods are never called.
+//
+// Default is used if cfg is nil.
+func Walk(v any, visitor Visitor, cfg *Config) {
+	if cfg == nil {
+		cfg = &Default
+	}
+	c := *cfg
+	c.DisableMethods = true
+	c.formatters = nil
+
+	s := &visitState{visitor: visitor}
+	s.walker = newWalker(&c, s)
+	s.walk(reflect.ValueOf(v), slotTop)
+}
+
+// visitState reports the values found by its walker to a Visitor.
+type visitState struct {
+	*walker
+	visitor Visitor
+
+	// last is the last value passed to header, which is reported to Leaf by
+	// nilValue.  terminal is set between enterPointer and leavePointer for
+	// chains which end without a value to walk.
+	last     reflect.Value
+	terminal bool
+}
+
+// visible returns v such that Interface can be called on it, even when it is
+// an unexported struct field.
+func visible(v reflect.Value) reflect.Value {
+	if v.IsValid() && !v.CanInterface() && !UnsafeDisabled {
+		v = unsafeReflectValue(v)
+	}
+	return v
+}
+
+func (s *visitState) writer() io.Writer { return io.Discard }
+func (s *visitState) context() Context  { return Context{} }
+
+func (s *visitState) header(v reflect.Value, sl slot, iface bool) {
+	s.last = v
+}
+
+func (s *visitState) lengths(v reflect.Value) {}
+
+func (s *visitState) leaf(v reflect.Value) {
+	s.visitor.Leaf(s.path, visible(v))
+}
+
+func (s *visitState) invalid() {
+	s.visitor.Leaf(s.path, reflect.Value{})
+}
+
+func (s *visitState) nilValue() {
+	if !s.terminal {
+		s.visitor.Leaf(s.path, visible(s.last))
+	}
+}
+
+func (s *visitState) circular()       {}
+func (s *visitState) maxDepth()       {}
+func (s *visitState) marker(b []byte) {}
+
+func (s *visitState) enterPointer(p *pointerInfo, sl slot, iface bool) {
+	ptr := Pointer{
+		Addrs:      p.chain,
+		Nil:        p.nilFound,
+		Circular:   p.cycleFound,
+		Shared:     p.sharedFound,
+		SharedPath: p.sharedPath,
+	}
+	if !p.nilFound {
+		ptr.Elem = visible(p.ve)
+	}
+	s.terminal = p.nilFound || p.cycleFound || p.sharedFound
+	s.visitor.Pointer(s.path, ptr)
+}
+
+func (s *visitState) leavePointer() {
+	s.terminal = false
+}
+
+func (s *visitState) empty(v reflect.Value) {
+	s.open(v)
+	s.close(v)
+}
+
+func (s *visitState) open(v reflect.Value) {
+	v = visible(v)
+	switch v.Kind() {
+	case reflect.Struct:
+		s.visitor.EnterStruct(s.path, v)
+	case reflect.Map:
+		s.visitor.EnterMap(s.path, v)
+	default:
+		s.visitor.EnterSlice(s.path, v)
+	}
+}
+
+func (s *visitState) close(v reflect.Value) {
+	v = visible(v)
+	switch v.Kind() {
+	case reflect.Struct:
+		s.visitor.LeaveStruct(s.path, v)
+	case reflect.Map:
+		s.visitor.LeaveMap(s.path, v)
+	default:
+		s.visitor.LeaveSlice(s.path, v)
+	}
+}
+
+func (s *visitState) bytes(v reflect.Value) bool { return false }
+func (s *visitState) beginItem(first bool)       {}
+func (s *visitState) endItems()                  {}
+func (s *visitState) more(n int)                 {}
+
+func (s *visitState) element(i int, v reflect.Value) {
+	s.visitor.Element(s.path, i, s.item(v))
+}
+
+func (s *visitState) field(name string, v reflect.Value) {
+	s.visitor.Field(s.path, name, s.item(v))
+}
+
+func (s *visitState) key(k, v reflect.Value) {
+	k, _ = unpack(k)
+	s.visitor.MapEntry(s.path, visible(k), s.item(v))
+}
+
+// item returns the element, field or map value v as it is passed to the
+// Visitor, which is invalid if it is a string matching RedactValues.
+func (s *visitState) item(v reflect.Value) reflect.Value {
+	v, _ = unpack(v)
+	if redactString(s.cfg, v) {
+		return reflect.Value{}
+	}
+	return visible(v)
+}
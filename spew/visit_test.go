@@ -0,0 +1,236 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// visitRecorder is a Visitor which records every call made to it, one per
+// line.
+type visitRecorder struct {
+	lines []string
+}
+
+func (r *visitRecorder) add(format string, a ...any) {
+	r.lines = append(r.lines, fmt.Sprintf(format, a...))
+}
+
+func (r *visitRecorder) EnterStruct(path string, v reflect.Value) {
+	r.add("enter struct %q", path)
+}
+
+func (r *visitRecorder) LeaveStruct(path string, v reflect.Value) {
+	r.add("leave struct %q", path)
+}
+
+func (r *visitRecorder) Field(path, name string, v reflect.Value) {
+	r.add("field %q %s valid=%v", path, name, v.IsValid())
+}
+
+func (r *visitRecorder) EnterMap(path string, v reflect.Value) {
+	r.add("enter map %q", path)
+}
+
+func (r *visitRecorder) LeaveMap(path string, v reflect.Value) {
+	r.add("leave map %q", path)
+}
+
+func (r *visitRecorder) MapEntry(path string, key, v reflect.Value) {
+	r.add("entry %q %v", path, key)
+}
+
+func (r *visitRecorder) EnterSlice(path string, v reflect.Value) {
+	r.add("enter slice %q", path)
+}
+
+func (r *visitRecorder) LeaveSlice(path string, v reflect.Value) {
+	r.add("leave slice %q", path)
+}
+
+func (r *visitRecorder) Element(path string, i int, v reflect.Value) {
+	r.add("element %q %d", path, i)
+}
+
+func (r *visitRecorder) Pointer(path string, p spew.Pointer) {
+	r.add("pointer %q len=%d nil=%v circular=%v shared=%v %s", path,
+		len(p.Addrs), p.Nil, p.Circular, p.Shared, p.SharedPath)
+}
+
+func (r *visitRecorder) Leaf(path string, v reflect.Value) {
+	if !v.IsValid() {
+		r.add("leaf %q invalid", path)
+		return
+	}
+	r.add("leaf %q %v", path, v)
+}
+
+// visitTest is used to describe a test to be performed against Walk.
+type visitTest struct {
+	line string // use line() to fill this
+	cfg  *spew.Config
+	in   any
+	want []string
+}
+
+// visitSecret has an unexported field, and a field which is redacted by name.
+type visitSecret struct {
+	Password string
+	hidden   int
+}
+
+// TestWalk executes all of the tests described by visitTests.
+func TestWalk(t *testing.T) {
+	cfgSorted := &spew.Config{SortKeys: true}
+	cfgDedupe := &spew.Config{DedupeShared: true}
+	cfgRedact := &spew.Config{Redact: []*regexp.Regexp{regexp.MustCompile("^Password$")}}
+	cfgValues := &spew.Config{RedactValues: []*regexp.Regexp{regexp.MustCompile("^s3cret$")}}
+	cfgDepth := &spew.Config{MaxDepth: 1}
+	cfgElements := &spew.Config{MaxElements: 1}
+
+	cycle := &walkNode{Name: "a"}
+	cycle.A = cycle
+	shared := &walkNode{Name: "s"}
+	pair := &walkNode{A: shared, B: shared}
+	var nilMap map[string]int
+	var nilIface any = (*int)(nil)
+
+	tests := []visitTest{
+		{line(), nil, nil, []string{`leaf "" invalid`}},
+		{line(), nil, 5, []string{`leaf "" 5`}},
+		{line(), nil, &nilIface, []string{
+			`pointer "" len=1 nil=true circular=false shared=false `,
+		}},
+		{line(), nil, nilMap, []string{`leaf "" map[]`}},
+		{line(), cfgSorted, map[string][]int{"b": {2}, "a": nil}, []string{
+			`enter map ""`,
+			`entry "[\"a\"]" a`,
+			`leaf "[\"a\"]" []`,
+			`entry "[\"b\"]" b`,
+			`enter slice "[\"b\"]"`,
+			`element "[\"b\"][0]" 0`,
+			`leaf "[\"b\"][0]" 2`,
+			`leave slice "[\"b\"]"`,
+			`leave map ""`,
+		}},
+		{line(), nil, visitSecret{"pw", 3}, []string{
+			`enter struct ""`,
+			`field ".Password" Password valid=true`,
+			`leaf ".Password" pw`,
+			`field ".hidden" hidden valid=true`,
+			`leaf ".hidden" 3`,
+			`leave struct ""`,
+		}},
+		{line(), cfgRedact, visitSecret{"pw", 3}, []string{
+			`enter struct ""`,
+			`field ".Password" Password valid=false`,
+			`field ".hidden" hidden valid=true`,
+			`leaf ".hidden" 3`,
+			`leave struct ""`,
+		}},
+		{line(), cfgValues, []string{"s3cret", "ok"}, []string{
+			`enter slice ""`,
+			`element "[0]" 0`,
+			`element "[1]" 1`,
+			`leaf "[1]" ok`,
+			`leave slice ""`,
+		}},
+		{line(), nil, cycle, []string{
+			`pointer "" len=1 nil=false circular=false shared=false `,
+			`enter struct ""`,
+			`field ".Name" Name valid=true`,
+			`leaf ".Name" a`,
+			`field ".A" A valid=true`,
+			`pointer ".A" len=1 nil=false circular=true shared=false `,
+			`field ".B" B valid=true`,
+			`pointer ".B" len=0 nil=true circular=false shared=false `,
+			`leave struct ""`,
+		}},
+		{line(), cfgDedupe, pair, []string{
+			`pointer "" len=1 nil=false circular=false shared=false `,
+			`enter struct ""`,
+			`field ".Name" Name valid=true`,
+			`leaf ".Name" `,
+			`field ".A" A valid=true`,
+			`pointer ".A" len=1 nil=false circular=false shared=false `,
+			`enter struct ".A"`,
+			`field ".A.Name" Name valid=true`,
+			`leaf ".A.Name" s`,
+			`field ".A.A" A valid=true`,
+			`pointer ".A.A" len=0 nil=true circular=false shared=false `,
+			`field ".A.B" B valid=true`,
+			`pointer ".A.B" len=0 nil=true circular=false shared=false `,
+			`leave struct ".A"`,
+			`field ".B" B valid=true`,
+			`pointer ".B" len=1 nil=false circular=false shared=true .A`,
+			`leave struct ""`,
+		}},
+		{line(), cfgDepth, [][]int{{1}}, []string{
+			`enter slice ""`,
+			`element "[0]" 0`,
+			`enter slice "[0]"`,
+			`leave slice "[0]"`,
+			`leave slice ""`,
+		}},
+		{line(), cfgElements, []int{1, 2, 3}, []string{
+			`enter slice ""`,
+			`element "[0]" 0`,
+			`leaf "[0]" 1`,
+			`leave slice ""`,
+		}},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		var r visitRecorder
+		spew.Walk(test.in, &r, test.cfg)
+		got := strings.Join(r.lines, "\n")
+		want := strings.Join(test.want, "\n")
+		if got != want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, got, want)
+		}
+	}
+}
+
+// leafVisitor embeds BaseVisitor to only record the paths and types of the
+// leaves it is passed.
+type leafVisitor struct {
+	spew.BaseVisitor
+	leaves []string
+}
+
+func (l *leafVisitor) Leaf(path string, v reflect.Value) {
+	l.leaves = append(l.leaves, path+"="+v.Type().String())
+}
+
+// TestWalkMethods ensures Walk reports values which implement Stringer rather
+// than calling their methods.
+func TestWalkMethods(t *testing.T) {
+	var l leafVisitor
+	spew.Walk([]any{stringer("s"), panicer(1)}, &l, nil)
+	got := strings.Join(l.leaves, " ")
+	want := "[0]=spew_test.stringer [1]=spew_test.panicer"
+	if got != want {
+		t.Errorf("got: %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,577 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"io"
+	"reflect"
+	"strings"
+)
+
+// slot identifies where a value is found, which the renderers use to decide
+// how much to output about it, such as whether to show its type.
+type slot int
+
+const (
+	slotTop     slot = iota // a value passed to Dump or the Formatter
+	slotElem                // an element of an array or slice
+	slotKey                 // a map key
+	slotValue               // a map value
+	slotField               // the value of a struct field
+	slotPointee             // the value a pointer points to
+)
+
+// pointerInfo describes a chain of pointers followed by the walker.
+type pointerInfo struct {
+	// ve is the value at the end of the chain, and chain is the addresses
+	// of all of the pointers in it.  indirects is the number of levels of
+	// indirection to ve.
+	ve        reflect.Value
+	chain     []uintptr
+	indirects int
+
+	// nilFound, cycleFound and sharedFound are set when the chain ends in
+	// nil, a circular reference or, with the DedupeShared option, a pointer
+	// which was already shown at sharedPath.
+	nilFound    bool
+	cycleFound  bool
+	sharedFound bool
+	sharedPath  string
+}
+
+// renderer produces the output for the values reported by a walker, such as
+// the multi-line style of Dump or the inline style of the Formatter.  All of
+// the decisions which do not depend on the style, such as which values,
+// fields and elements to output, are made by the walker, so that every
+// option behaves the same way in every style.
+type renderer interface {
+	// writer returns the Writer output goes to, which is also passed to
+	// custom formatters and methods.
+	writer() io.Writer
+
+	// context returns the Context passed to custom formatters and SpewDump
+	// methods.
+	context() Context
+
+	// header outputs what precedes v, which is found in slot s, such as its
+	// type.  iface is set when v was unpacked from an interface.  lengths
+	// outputs its length and capacity.
+	header(v reflect.Value, s slot, iface bool)
+	lengths(v reflect.Value)
+
+	// leaf outputs v, which is not a pointer, interface or collection.
+	leaf(v reflect.Value)
+
+	// invalid, nilValue, circular and maxDepth output markers in place of
+	// invalid values, nil values, circular references and values nested
+	// deeper than the MaxDepth option.  marker outputs any other marker.
+	invalid()
+	nilValue()
+	circular()
+	maxDepth()
+	marker(b []byte)
+
+	// enterPointer outputs what precedes the value at the end of the chain
+	// of pointers p, which is found in slot s, and leavePointer what follows
+	// it.
+	enterPointer(p *pointerInfo, s slot, iface bool)
+	leavePointer()
+
+	// empty outputs the empty array, slice, map or struct v in its
+	// abbreviated form.  Otherwise, open and close output what precedes and
+	// follows the items within v.
+	empty(v reflect.Value)
+	open(v reflect.Value)
+	close(v reflect.Value)
+
+	// bytes outputs the byte array or slice v in a style of its own,
+	// reporting whether it did.  Otherwise, its bytes are output as items.
+	bytes(v reflect.Value) bool
+
+	// beginItem outputs what precedes each element, map entry, field or
+	// marker of elided items within a collection, including the separator
+	// from the item before it unless first is set.  It is only called once
+	// the item is sure to be output, so that output which is cut short never
+	// ends with a separator.  endItems outputs what follows the last item
+	// which was output.
+	beginItem(first bool)
+	endItems()
+
+	// more outputs the marker for more elided items.
+	more(n int)
+
+	// element is called before the element at index i of an array or slice
+	// is walked, which is v.  field outputs the name of a struct field
+	// before its value v is walked, and key outputs the map key k, followed
+	// by the separator from its value v.  v is invalid for redacted fields
+	// and map values.
+	element(i int, v reflect.Value)
+	field(name string, v reflect.Value)
+	key(k, v reflect.Value)
+}
+
+// walker traverses values by reflection on behalf of a renderer.  It is
+// shared by Dump and the Formatter, and takes care of following pointers
+// while detecting circular and shared references, calling custom formatters
+// and methods, redaction, leaving out fields, and the MaxDepth, MaxElements,
+// MaxBytes and similar limits.
+type walker struct {
+	cfg      *Config
+	r        renderer
+	depth    int
+	pointers map[uintptr]int
+
+	// hex is set while walking a field with the hex spew struct tag.
+	hex bool
+
+	// path is the path of the value being walked, such as .Items[3].Name.
+	path string
+
+	// names are the names of the struct fields and string map keys along
+	// path, following an empty name for the value at the top, which are
+	// matched by the IncludeFields and ExcludeFields options.  included is
+	// set within values which IncludeFields matches.
+	names    []string
+	included bool
+
+	// shown maps the address of every pointer which has been followed to
+	// the path it was first shown at.  It is only used when the DedupeShared
+	// option is set.
+	shown map[uintptr]string
+
+	// depthLimit is the limit in effect in place of MaxDepth, which is
+	// replaced by DepthOverrides within the values they match.  overridden
+	// flags the DepthOverrides in effect.
+	depthLimit int
+	overridden []bool
+
+	// counter counts the output, if it is being counted, and stops the walk
+	// on write errors and when the output is cut short.
+	counter *countingWriter
+}
+
+// newWalker returns a walker for cfg which reports to r.
+func newWalker(cfg *Config, r renderer) *walker {
+	w := &walker{cfg: cfg, r: r, pointers: make(map[uintptr]int), names: []string{""},
+		depthLimit: cfg.MaxDepth}
+	if len(cfg.DepthOverrides) > 0 {
+		w.overridden = make([]bool, len(cfg.DepthOverrides))
+	}
+	if cfg.DedupeShared {
+		w.shown = make(map[uintptr]string)
+	}
+	return w
+}
+
+// unpack returns the value inside of v if it is a non-nil interface, and
+// whether v is an interface.  This is useful for data types like structs,
+// arrays, slices, and maps which can contain varying types packed inside an
+// interface.
+func unpack(v reflect.Value) (reflect.Value, bool) {
+	if v.Kind() != reflect.Interface {
+		return v, false
+	}
+	if !v.IsNil() {
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// followPointers dereferences pointers and unpacks interfaces down the chain
+// starting at v.  It returns the value at the end of the chain, the addresses
+// of all of the pointers in the chain, and whether the chain ended in nil.
+// Each address is passed to seen before it is dereferenced, and the chain ends
+// early, leaving that pointer as the returned value, if seen returns true.
+func followPointers(v reflect.Value, seen func(addr uintptr) bool) (ve reflect.Value, chain []uintptr, nilFound, seenFound bool) {
+	ve = v
+	for ve.Kind() == reflect.Ptr {
+		if ve.IsNil() {
+			nilFound = true
+			break
+		}
+		addr := ve.Pointer()
+		chain = append(chain, addr)
+		if seen(addr) {
+			seenFound = true
+			break
+		}
+
+		ve = ve.Elem()
+		if ve.Kind() == reflect.Interface {
+			if ve.IsNil() {
+				nilFound = true
+				break
+			}
+			ve = ve.Elem()
+		}
+	}
+	return ve, chain, nilFound, seenFound
+}
+
+// isAncestorPath returns whether the value at path ancestor contains the value
+// at path, or is the same value.
+func isAncestorPath(ancestor, path string) bool {
+	if !strings.HasPrefix(path, ancestor) {
+		return false
+	}
+	rest := path[len(ancestor):]
+	return rest == "" || rest[0] == '.' || rest[0] == '['
+}
+
+// walkPtr handles pointers by indirecting them as necessary.
+func (w *walker) walkPtr(v reflect.Value, s slot, iface bool) {
+	// Remove pointers at or below the current depth from map used to detect
+	// circular refs.
+	for k, depth := range w.pointers {
+		if depth >= w.depth {
+			delete(w.pointers, k)
+		}
+	}
+
+	// Figure out how many levels of indirection there are by dereferencing
+	// pointers and unpacking interfaces down the chain while detecting circular
+	// references.  When DedupeShared is set, pointers which have already been
+	// shown elsewhere end the chain too.
+	//
+	// The depth-pruned pointers map can't tell the ancestors of a value from
+	// values shown earlier at a shallower depth, so when DedupeShared is set
+	// a pointer is only circular if it was shown at an ancestor of the
+	// current path.
+	var p pointerInfo
+	p.ve, p.chain, p.nilFound, p.cycleFound = followPointers(v, func(addr uintptr) bool {
+		if w.cfg.DedupeShared {
+			if path, ok := w.shown[addr]; ok {
+				p.sharedPath, p.sharedFound = path, !isAncestorPath(path, w.path)
+				return true
+			}
+			w.shown[addr] = w.path
+		} else if pd, ok := w.pointers[addr]; ok && pd < w.depth {
+			return true
+		}
+		w.pointers[addr] = w.depth
+		return false
+	})
+	if p.sharedFound {
+		p.cycleFound = false
+	}
+	p.indirects = len(p.chain)
+	if p.cycleFound || p.sharedFound {
+		p.indirects--
+	}
+
+	w.r.enterPointer(&p, s, iface)
+	switch {
+	case p.nilFound:
+		w.r.nilValue()
+
+	case p.sharedFound:
+		w.r.marker([]byte("<see " + p.sharedPath + ">"))
+
+	case p.cycleFound:
+		w.r.circular()
+
+	default:
+		w.walk(p.ve, slotPointee)
+	}
+	w.r.leavePointer()
+}
+
+// walkRedacted outputs marker in place of the value v in slot s.
+func (w *walker) walkRedacted(v reflect.Value, s slot, marker []byte) {
+	v, iface := unpack(v)
+	w.r.header(v, s, iface)
+	w.r.marker(marker)
+}
+
+// walk is the main workhorse, which walks the value v found in slot s.  It
+// uses the passed reflect value to figure out what kind of object we are
+// dealing with and reports it to the renderer appropriately.  It is a
+// recursive function, however circular data structures are detected and
+// handled properly.
+func (w *walker) walk(v reflect.Value, s slot) {
+	v, iface := unpack(v)
+
+	// Handle invalid reflect values immediately.
+	kind := v.Kind()
+	if kind == reflect.Invalid {
+		w.r.invalid()
+		return
+	}
+
+	// Apply the depth limit of the first DepthOverride matching v until it
+	// has been walked.
+	if i := w.depthOverride(v); i >= 0 {
+		depthLimit := w.depthLimit
+		w.depthLimit, w.overridden[i] = 0, true
+		if n := w.cfg.DepthOverrides[i].MaxDepth; n != 0 {
+			w.depthLimit = w.depth + n
+		}
+		defer func() { w.depthLimit, w.overridden[i] = depthLimit, false }()
+	}
+
+	// Handle pointers specially.
+	if kind == reflect.Ptr {
+		w.walkPtr(v, s, iface)
+		return
+	}
+
+	w.r.header(v, s, iface)
+
+	// Output strings matching RedactValues without their content.
+	if redactString(w.cfg, v) {
+		w.r.marker(redactedLen(v))
+		return
+	}
+
+	w.r.lengths(v)
+
+	// Call the custom formatter registered for the type if there is one.
+	if len(w.cfg.formatters) > 0 && kind != reflect.Interface {
+		if handled := handleFormatter(w.cfg, w.r.writer(), v, w.r.context()); handled {
+			return
+		}
+	}
+
+	// Call SpewDumper/Stringer/error interfaces if they exist and the handle
+	// methods flag is enabled.
+	if !w.cfg.DisableMethods && kind != reflect.Interface {
+		if handled := handleMethods(w.cfg, w.r.writer(), v, w.r.context()); handled {
+			return
+		}
+	}
+
+	switch kind {
+	case reflect.Interface:
+		// The only time we should get here is for nil interfaces due to
+		// unpack calls.
+		w.r.nilValue()
+
+	case reflect.Slice:
+		if v.IsNil() {
+			w.r.nilValue()
+			break
+		}
+		if v.Len() == 0 && w.cfg.AbbreviateEmpty {
+			w.r.empty(v)
+			break
+		}
+		fallthrough
+
+	case reflect.Array:
+		w.walkCollection(v, func() {
+			if !w.r.bytes(v) {
+				w.walkSlice(v)
+			}
+		})
+
+	case reflect.Map:
+		// nil maps should be indicated as different than empty maps
+		if v.IsNil() {
+			w.r.nilValue()
+			break
+		}
+		if v.Len() == 0 && w.cfg.AbbreviateEmpty {
+			w.r.empty(v)
+			break
+		}
+		w.walkCollection(v, func() { w.walkMap(v) })
+
+	case reflect.Struct:
+		if v.NumField() == 0 && w.cfg.AbbreviateEmpty {
+			w.r.empty(v)
+			break
+		}
+		w.walkCollection(v, func() { w.walkStruct(v) })
+
+	default:
+		w.r.leaf(v)
+	}
+}
+
+// walkCollection outputs the array, slice, map or struct v, calling items to
+// walk its items unless it is nested deeper than MaxDepth or the DepthOverride
+// in effect.
+func (w *walker) walkCollection(v reflect.Value, items func()) {
+	w.r.open(v)
+	w.depth++
+	if (w.depthLimit != 0) && (w.depth > w.depthLimit) {
+		w.r.maxDepth()
+	} else {
+		items()
+	}
+	w.depth--
+	w.r.close(v)
+}
+
+// depthOverride returns the index of the first of the DepthOverrides which
+// matches v and is not already in effect, or -1 if there is none.
+func (w *walker) depthOverride(v reflect.Value) int {
+	for i, o := range w.cfg.DepthOverrides {
+		if w.overridden[i] || (o.Type != nil && o.Type != v.Type()) {
+			continue
+		}
+		if o.Path != "" {
+			if match, _ := matchFields([]string{o.Path}, w.names); !match {
+				continue
+			}
+		}
+		return i
+	}
+	return -1
+}
+
+// walkItems calls item for each of the n items of a collection, eliding
+// those in excess of max, and stops once the output is cut short.
+func (w *walker) walkItems(n, max int, item func(i int)) {
+	head, tail := truncate(w.cfg, n, max)
+	begun := false
+	for i := 0; i < n; i++ {
+		if w.counter.stop() {
+			break
+		}
+		w.r.beginItem(i == 0)
+		begun = true
+		if i == head && head+tail < n {
+			w.r.more(n - head - tail)
+			i = n - tail - 1
+			continue
+		}
+		item(i)
+	}
+	if begun {
+		w.r.endItems()
+	}
+}
+
+// walkSlice walks the elements of the array or slice v.  Byte arrays and
+// slices are limited like strings.
+func (w *walker) walkSlice(v reflect.Value) {
+	max := w.cfg.MaxElements
+	if v.Type().Elem().Kind() == reflect.Uint8 {
+		max = w.cfg.MaxStringLen
+	}
+	path := w.path
+	w.walkItems(v.Len(), max, func(i int) {
+		w.path = indexPath(path, i)
+		w.r.element(i, v.Index(i))
+		w.walk(v.Index(i), slotElem)
+	})
+	w.path = path
+}
+
+// walkMap walks the entries of the map v.
+func (w *walker) walkMap(v reflect.Value) {
+	keys := mapKeys(w.cfg, v)
+	if len(w.cfg.IncludeFields) > 0 || len(w.cfg.ExcludeFields) > 0 {
+		kept := keys[:0]
+		for _, key := range keys {
+			uk, _ := unpack(key)
+			if uk.Kind() == reflect.String {
+				if skip, _ := w.filterName(uk.String(), v.Type().Elem()); skip {
+					continue
+				}
+			}
+			kept = append(kept, key)
+		}
+		keys = kept
+	}
+	path, names, included := w.path, w.names, w.included
+	w.walkItems(len(keys), w.cfg.MaxElements, func(i int) {
+		key, _ := unpack(keys[i])
+		value := v.MapIndex(keys[i])
+		w.path = keyPath(w.cfg, path, key)
+		w.names, w.included = names, included
+		if key.Kind() == reflect.String {
+			_, w.included = w.filterName(key.String(), v.Type().Elem())
+			w.names = appendName(names, key.String())
+		}
+		if key.Kind() == reflect.String && redactName(w.cfg, key.String(), w.path) {
+			w.r.key(keys[i], reflect.Value{})
+			uv, _ := unpack(value)
+			w.walkRedacted(value, slotValue, redactedLen(uv))
+			return
+		}
+		w.r.key(keys[i], value)
+		w.walk(value, slotValue)
+	})
+	w.path, w.names, w.included = path, names, included
+}
+
+// walkStruct walks the fields of the struct v.
+func (w *walker) walkStruct(v reflect.Value) {
+	vt := v.Type()
+	fields := structFields(w.cfg, v)
+	if len(w.cfg.IncludeFields) > 0 || len(w.cfg.ExcludeFields) > 0 {
+		kept := fields[:0]
+		for _, field := range fields {
+			vtf := vt.Field(field.index)
+			if skip, _ := w.filterName(vtf.Name, vtf.Type); !skip {
+				kept = append(kept, field)
+			}
+		}
+		fields = kept
+	}
+	path, names, included := w.path, w.names, w.included
+	begun := false
+	for _, field := range fields {
+		if field.hidden {
+			continue
+		}
+		if w.counter.stop() {
+			break
+		}
+		vtf := vt.Field(field.index)
+		value := v.Field(field.index)
+		w.path = path + "." + vtf.Name
+		_, w.included = w.filterName(vtf.Name, vtf.Type)
+		w.names = appendName(names, vtf.Name)
+		w.r.beginItem(!begun)
+		begun = true
+		switch {
+		case field.redact:
+			w.r.field(vtf.Name, reflect.Value{})
+			w.walkRedacted(value, slotField, redactedBytes)
+
+		case redactName(w.cfg, vtf.Name, w.path):
+			w.r.field(vtf.Name, reflect.Value{})
+			uv, _ := unpack(value)
+			w.walkRedacted(value, slotField, redactedLen(uv))
+
+		default:
+			w.r.field(vtf.Name, value)
+			hex := w.hex
+			w.hex = hex || field.hex
+			w.walk(value, slotField)
+			w.hex = hex
+		}
+		w.included = included
+	}
+	if begun {
+		w.r.endItems()
+	}
+	w.path, w.names, w.included = path, names, included
+}
+
+// methodContext returns the Context passed to custom formatters and SpewDump
+// methods, with the indentation of the current depth unless inline is set.
+func (w *walker) methodContext(inline bool) Context {
+	ctx := Context{Config: w.cfg, Depth: w.depth, Inline: inline, done: w.counter.ctxDone()}
+	if !inline {
+		ctx.Indent = strings.Repeat(w.cfg.Indent, w.depth)
+	}
+	return ctx
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// walkNode is used to test pointers shared by multiple fields.
+type walkNode struct {
+	Name string
+	A, B *walkNode
+}
+
+// TestWalkOptions executes all of the tests described by walkTests.
+func TestWalkOptions(t *testing.T) {
+	cfgNoTypes := &spew.Config{DisableTypes: true}
+	cfgNoAddrs := &spew.Config{DisablePointerAddresses: true}
+	cfgCommas := &spew.Config{Commas: true, TrailingCommas: true}
+	cfgDedupe := &spew.Config{Indent: " ", DisablePointerAddresses: true, DedupeShared: true}
+	cfgOrdinals := &spew.Config{PointerOrdinals: true, DedupeShared: true}
+
+	shared := &walkNode{Name: "s"}
+	v := walkNode{Name: "v", A: shared, B: shared}
+	list := []any{1, map[string]int{"a": 1}}
+
+	tests := []outputTest{
+		{line(), cfgNoTypes, "%#v", list, "[1 map[a:1]]"},
+		{line(), cfgNoTypes, "%#v", walkNode{Name: "v"}, "{Name:v A:<nil> B:<nil>}"},
+		{line(), cfgNoTypes, "%#v", nil, "<nil>"},
+		{line(), cfgNoAddrs, "%+v", v, "{Name:v A:<*>{Name:s A:<nil> B:<nil>} B:<*>{Name:s A:<nil> B:<nil>}}"},
+		{line(), cfgCommas, "%v", list, "[1,map[a:1,],]"},
+		{line(), cfgCommas, "%v", []int{}, "[]"},
+		{line(), cfgDedupe, "%v", v, "{v <*>{s <nil> <nil>} <*><see .A>}"},
+		{line(), cfgDedupe, "", v, "(spew_test.walkNode) {\n" +
+			" Name: (string) (len=1) \"v\",\n" +
+			" A: (*spew_test.walkNode)({\n" +
+			"  Name: (string) (len=1) \"s\",\n" +
+			"  A: (*spew_test.walkNode)(<nil>),\n" +
+			"  B: (*spew_test.walkNode)(<nil>)\n" +
+			" }),\n" +
+			" B: (*spew_test.walkNode)(<see .A>)\n" +
+			"}\n"},
+		{line(), cfgOrdinals, "%+v", v, "{Name:v A:<*>(&1){Name:s A:<nil> B:<nil>} B:<*>(&1)<see .A>}"},
+	}
+
+	runOutputTests(t, tests)
+}
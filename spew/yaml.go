@@ -0,0 +1,465 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Some constants in the form of bytes used by the YAML output.
+var (
+	yamlDocStartBytes = []byte("---")
+	yamlNullBytes     = []byte("null")
+	yamlDashBytes     = []byte("-")
+	yamlEmptyMapBytes = []byte("{}")
+	yamlEmptySeqBytes = []byte("[]")
+)
+
+// yamlPlainRE matches strings which can be output as plain YAML scalars
+// without being mistaken for another type or containing special characters.
+var yamlPlainRE = regexp.MustCompile(`^[A-Za-z_/][A-Za-z0-9_./-]*( [A-Za-z0-9_./-]+)*$`)
+
+// yamlReserved holds the plain scalars which YAML 1.1 or 1.2 would read as
+// something other than a string.
+var yamlReserved = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true,
+	"n": true, "N": true, "no": true, "No": true, "NO": true,
+	"true": true, "True": true, "TRUE": true,
+	"false": true, "False": true, "FALSE": true,
+	"on": true, "On": true, "ON": true,
+	"off": true, "Off": true, "OFF": true,
+	"null": true, "Null": true, "NULL": true,
+}
+
+// yamlState contains information about the state of a YAML output
+// operation.
+type yamlState struct {
+	w                io.Writer
+	depth            int
+	indent           []byte
+	ignoreNextIndent bool
+	cfg              *Config
+
+	// refs counts how many times each pointer is reached, and anchors
+	// holds the anchor name of each pointer reached more than once which
+	// has already been output.  lastAnchor is the most recently written
+	// anchor name.
+	refs       map[uintptr]int
+	anchors    map[uintptr]string
+	lastAnchor string
+
+	// hex is set while outputting a field with the hex spew struct tag.
+	hex bool
+
+	// path is the path of the value being output in the form Dump uses,
+	// such as .Items[3].Name, which the Redact option is matched against.
+	path string
+}
+
+// unpackValue returns values inside of non-nil interfaces when possible.
+func (y *yamlState) unpackValue(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// writeIndent performs indentation according to the depth level.
+func (y *yamlState) writeIndent() {
+	if y.ignoreNextIndent {
+		y.ignoreNextIndent = false
+		return
+	}
+	y.w.Write(bytes.Repeat(y.indent, y.depth))
+}
+
+// yamlString returns s as a YAML scalar, quoting it if it would otherwise be
+// read as something other than the same string.  Go quoted strings use a
+// subset of the YAML double-quoted escapes.
+func yamlString(s string) string {
+	if yamlPlainRE.MatchString(s) && !yamlReserved[s] {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// scalar outputs a scalar after a mapping key or sequence dash.
+func (y *yamlState) scalar(s string) {
+	y.w.Write(spaceBytes)
+	y.w.Write([]byte(s))
+	y.w.Write(newlineBytes)
+}
+
+// methodString returns the result of invoking the error or Stringer interface
+// on v, if there is one and it is not configured to continue on to the
+// underlying value.
+func (y *yamlState) methodString(v reflect.Value) (string, bool) {
+	var buf bytes.Buffer
+	if handled := handleMethods(y.cfg, &buf, v, Context{Config: y.cfg, Inline: true}); handled {
+		return buf.String(), true
+	}
+	return "", false
+}
+
+// yamlPtr handles output of pointers by indirecting them as necessary.  The
+// first time a pointer which is reached more than once is output, it is
+// given an anchor, and every time after that it is output as an alias of the
+// anchor.
+func (y *yamlState) yamlPtr(v reflect.Value, compact, anchored bool) {
+	if v.IsNil() {
+		y.scalar(string(yamlNullBytes))
+		return
+	}
+
+	addr := v.Pointer()
+	if y.refs[addr] > 1 {
+		if name, ok := y.anchors[addr]; ok {
+			y.scalar("*" + name)
+			return
+		}
+
+		// A node can only have a single anchor, so any pointers further
+		// down the same chain share it.
+		if !anchored {
+			y.lastAnchor = fmt.Sprintf("id%03d", len(y.anchors)+1)
+			y.w.Write(spaceBytes)
+			y.w.Write([]byte("&" + y.lastAnchor))
+		}
+		y.anchors[addr] = y.lastAnchor
+		anchored = true
+	}
+	y.yaml(v.Elem(), compact, anchored)
+}
+
+// yaml is the main workhorse for YAML output.  It outputs v following a
+// mapping key or sequence dash which has already been written.  Scalars and
+// empty collections are output on the same line, and collections are output
+// as blocks on the following lines.  If compact is set, the first entry of a
+// block is output on the same line instead, as is usual for sequences of
+// mappings.  If anchored is set, an anchor has already been written, so
+// blocks always start on the following line.
+func (y *yamlState) yaml(v reflect.Value, compact, anchored bool) {
+	v = y.unpackValue(v)
+	kind := v.Kind()
+	switch kind {
+	case reflect.Invalid:
+		y.scalar(string(yamlNullBytes))
+		return
+
+	case reflect.Ptr:
+		y.yamlPtr(v, compact, anchored)
+		return
+	}
+
+	// Call Stringer/error interfaces if they exist and the handle methods flag
+	// is enabled.
+	if !y.cfg.DisableMethods && kind != reflect.Interface {
+		if s, handled := y.methodString(v); handled {
+			y.scalar(yamlString(s))
+			return
+		}
+	}
+
+	switch kind {
+	case reflect.Bool:
+		y.scalar(strconv.FormatBool(v.Bool()))
+
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		y.scalar(formatInt(v, y.hex))
+
+	case reflect.Float32, reflect.Float64:
+		bitSize := 64
+		if kind == reflect.Float32 {
+			bitSize = 32
+		}
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			y.scalar(".nan")
+		case math.IsInf(f, 1):
+			y.scalar(".inf")
+		case math.IsInf(f, -1):
+			y.scalar("-.inf")
+		default:
+			y.scalar(strconv.FormatFloat(f, 'g', -1, bitSize))
+		}
+
+	case reflect.Complex64, reflect.Complex128:
+		bitSize := 64
+		if kind == reflect.Complex64 {
+			bitSize = 32
+		}
+		var buf bytes.Buffer
+		printComplex(&buf, v.Complex(), bitSize)
+		y.scalar(strconv.Quote(buf.String()))
+
+	case reflect.String:
+		if redactString(y.cfg, v) {
+			y.scalar(yamlString(string(redactedLen(v))))
+			break
+		}
+		y.scalar(yamlString(v.String()))
+
+	case reflect.Interface:
+		// The only time we should get here is for nil interfaces due to
+		// unpackValue calls.
+		y.scalar(string(yamlNullBytes))
+
+	case reflect.Slice:
+		if v.IsNil() {
+			y.scalar(string(yamlNullBytes))
+			break
+		}
+		// Byte slices are output as binary scalars.
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			buf := make([]byte, v.Len())
+			for i := range buf {
+				buf[i] = uint8(v.Index(i).Uint())
+			}
+			y.scalar("!!binary " + base64.StdEncoding.EncodeToString(buf))
+			break
+		}
+		fallthrough
+
+	case reflect.Array:
+		numEntries := v.Len()
+		if numEntries == 0 {
+			y.scalar(string(yamlEmptySeqBytes))
+			break
+		}
+		if y.maxDepth() {
+			break
+		}
+		y.startBlock(compact, anchored)
+		path := y.path
+		for i := 0; i < numEntries; i++ {
+			y.writeIndent()
+			y.w.Write(yamlDashBytes)
+			y.path = indexPath(path, i)
+			y.yaml(v.Index(i), true, false)
+		}
+		y.path = path
+		y.depth--
+
+	case reflect.Map:
+		if v.IsNil() {
+			y.scalar(string(yamlNullBytes))
+			break
+		}
+		if v.Len() == 0 {
+			y.scalar(string(yamlEmptyMapBytes))
+			break
+		}
+		if y.maxDepth() {
+			break
+		}
+		y.startBlock(compact, anchored)
+		keys := v.MapKeys()
+		if y.cfg.SortKeys {
+			sortValues(keys, y.cfg)
+		}
+		path := y.path
+		for i, key := range keys {
+			// Keys are always output as strings, formatted the same way
+			// the Formatter would format them.
+			name := ""
+			uk := y.unpackValue(key)
+			switch {
+			case redactString(y.cfg, uk):
+				name = redactedKey(uk, i)
+			case key.Kind() == reflect.String:
+				name = key.String()
+			default:
+				name = sprintValue(y.cfg, uk, "")
+			}
+			y.key(name)
+			y.path = keyPath(y.cfg, path, uk)
+			if key.Kind() == reflect.String && redactName(y.cfg, key.String(), y.path) {
+				y.scalar(yamlString(string(redactedLen(y.unpackValue(v.MapIndex(key))))))
+				continue
+			}
+			y.yaml(v.MapIndex(key), false, false)
+		}
+		y.path = path
+		y.depth--
+
+	case reflect.Struct:
+		vt := v.Type()
+		numFields := v.NumField()
+		fields := make([]structField, 0, numFields)
+		for i := 0; i < numFields; i++ {
+			// StructField has an IsExported() method, but only in 1.17+.
+			if y.cfg.DisableUnexported && vt.Field(i).PkgPath != "" {
+				continue
+			}
+			if field, ok := fieldTag(v, i); ok {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) == 0 {
+			y.scalar(string(yamlEmptyMapBytes))
+			break
+		}
+		if y.maxDepth() {
+			break
+		}
+		y.startBlock(compact, anchored)
+		path := y.path
+		for _, field := range fields {
+			name := vt.Field(field.index).Name
+			fv := v.Field(field.index)
+			y.key(name)
+			y.path = path + "." + name
+			switch {
+			case field.redact:
+				y.scalar(yamlString(string(redactedBytes)))
+			case redactName(y.cfg, name, y.path):
+				y.scalar(yamlString(string(redactedLen(y.unpackValue(fv)))))
+			default:
+				hex := y.hex
+				y.hex = hex || field.hex
+				y.yaml(fv, false, false)
+				y.hex = hex
+			}
+		}
+		y.path = path
+		y.depth--
+
+	case reflect.Uintptr:
+		var buf bytes.Buffer
+		printHexPtr(&buf, uintptr(v.Uint()))
+		y.scalar(yamlString(buf.String()))
+
+	case reflect.UnsafePointer, reflect.Chan:
+		if v.Pointer() == 0 {
+			y.scalar(string(yamlNullBytes))
+			break
+		}
+		var buf bytes.Buffer
+		printHexPtr(&buf, v.Pointer())
+		y.scalar(yamlString(buf.String()))
+
+	case reflect.Func:
+		if v.IsNil() {
+			y.scalar(string(yamlNullBytes))
+			break
+		}
+		var buf bytes.Buffer
+		if fn := runtime.FuncForPC(v.Pointer()); y.cfg.FuncSymbols && fn != nil {
+			file, line := fn.FileLine(v.Pointer())
+			fmt.Fprintf(&buf, "%s[%s:%d]", filepath.Base(fn.Name()), filepath.Base(file), line)
+		} else {
+			printHexPtr(&buf, v.Pointer())
+		}
+		y.scalar(yamlString(buf.String()))
+
+	// There were not any other types at the time this code was written, but
+	// fall back to letting the Formatter handle it in case any new types are
+	// added.
+	default:
+		y.scalar(yamlString(sprintValue(y.cfg, v, "")))
+	}
+}
+
+// maxDepth outputs a marker and returns true if descending into a collection
+// would exceed the MaxDepth option.
+func (y *yamlState) maxDepth() bool {
+	if y.cfg.MaxDepth != 0 && y.depth+1 >= y.cfg.MaxDepth {
+		y.scalar(strconv.Quote(string(maxNewlineBytes[:len(maxNewlineBytes)-1])))
+		return true
+	}
+	return false
+}
+
+// startBlock starts a block collection one level deeper than the current one.
+// The caller is responsible for decrementing the depth when the block ends.
+func (y *yamlState) startBlock(compact, anchored bool) {
+	y.depth++
+	if compact && !anchored {
+		y.w.Write(y.indent[1:])
+		y.ignoreNextIndent = true
+		return
+	}
+	y.w.Write(newlineBytes)
+}
+
+// key outputs a mapping key at the current depth.
+func (y *yamlState) key(name string) {
+	y.writeIndent()
+	y.w.Write([]byte(yamlString(name)))
+	y.w.Write(colonBytes)
+}
+
+// yamlIndent returns the indentation to use for YAML output.  YAML requires
+// spaces, and compact sequence entries need at least two of them to line up,
+// so the Indent option is only used if it satisfies both.
+func yamlIndent(indent string) []byte {
+	if len(indent) < 2 || strings.Trim(indent, " ") != "" {
+		indent = "  "
+	}
+	return []byte(indent)
+}
+
+// fyaml is a helper function to consolidate the logic from the various public
+// methods which take varying writers and config states.
+func fyaml(cfg *Config, w io.Writer, a any) {
+	y := yamlState{w: w, cfg: cfg, indent: yamlIndent(cfg.Indent)}
+	y.refs = make(map[uintptr]int)
+	y.anchors = make(map[uintptr]string)
+	v := reflect.ValueOf(a)
+	countPointerRefs(cfg, v, y.refs)
+
+	// The top-level value follows the document start marker, so scalars are
+	// output on the same line and collections start at the first column.
+	y.w.Write(yamlDocStartBytes)
+	y.depth = -1
+	y.yaml(v, false, false)
+}
+
+// Fyaml formats and displays the passed argument to io.Writer w as a YAML
+// document.  It formats exactly the same as Syaml.
+func Fyaml(w io.Writer, a any) {
+	fyaml(&Default, w, a)
+}
+
+// Syaml returns a string with the passed argument formatted as a YAML
+// document, so that it reads like a configuration file.  Values are walked
+// the same way Dump walks them, but types, lengths and pointer addresses are
+// not shown.  Instead, every pointer which is reached more than once,
+// including through circular references, is given an &idNNN anchor where it
+// is first output and is output as an *idNNN alias everywhere after that.
+//
+// Map keys which are not strings are formatted inline the same way the
+// Formatter would format them, and byte slices are output as !!binary
+// scalars.  The output is indented with the Indent option if it consists of
+// at least two spaces, or with two spaces otherwise.
+func Syaml(a any) string {
+	var buf bytes.Buffer
+	fyaml(&Default, &buf, a)
+	return buf.String()
+}
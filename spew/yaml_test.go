@@ -0,0 +1,126 @@
+/*
+ * Copyright (c) 2013-2016 Dave Collins <dave@davec.name>
+ *
+ * Permission to use, copy, modify, and distribute this software for any
+ * purpose with or without fee is hereby granted, provided that the above
+ * copyright notice and this permission notice appear in all copies.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS" AND THE AUTHOR DISCLAIMS ALL WARRANTIES
+ * WITH REGARD TO THIS SOFTWARE INCLUDING ALL IMPLIED WARRANTIES OF
+ * MERCHANTABILITY AND FITNESS. IN NO EVENT SHALL THE AUTHOR BE LIABLE FOR
+ * ANY SPECIAL, DIRECT, INDIRECT, OR CONSEQUENTIAL DAMAGES OR ANY DAMAGES
+ * WHATSOEVER RESULTING FROM LOSS OF USE, DATA OR PROFITS, WHETHER IN AN
+ * ACTION OF CONTRACT, NEGLIGENCE OR OTHER TORTIOUS ACTION, ARISING OUT OF
+ * OR IN CONNECTION WITH THE USE OR PERFORMANCE OF THIS SOFTWARE.
+ */
+
+package spew_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/thockin/go-spew/spew"
+)
+
+// yamlTest is used to describe a test to be performed against the Syaml
+// method.
+type yamlTest struct {
+	line string // use line() to fill this
+	cfg  *spew.Config
+	in   any
+	want string
+}
+
+// yamlNode is used to test structs, nesting and shared pointers.
+type yamlNode struct {
+	Name  string
+	Items []*yamlNode
+	Up    *yamlNode
+	id    int
+}
+
+// TestYAML executes all of the tests described by yamlTests.
+func TestYAML(t *testing.T) {
+	cfgSorted := &spew.Config{SortKeys: true}
+	cfgIndent := &spew.Config{Indent: "    "}
+	cfgMaxDepth := &spew.Config{MaxDepth: 1}
+	cfgNoMethods := &spew.Config{DisableMethods: true}
+	cfgNoUnexported := &spew.Config{DisableUnexported: true}
+
+	tree := &yamlNode{Name: "root"}
+	tree.Items = []*yamlNode{{Name: "a", Up: tree}, {Name: "b", Up: tree}}
+	shared := &yamlNode{Name: "shared"}
+	twice := []*yamlNode{shared, shared}
+
+	tests := []yamlTest{
+		{line(), cfgSorted, nil, "--- null\n"},
+		{line(), cfgSorted, 1, "--- 1\n"},
+		{line(), cfgSorted, true, "--- true\n"},
+		{line(), cfgSorted, "plain text", "--- plain text\n"},
+		{line(), cfgSorted, "yes", "--- \"yes\"\n"},
+		{line(), cfgSorted, "12", "--- \"12\"\n"},
+		{line(), cfgSorted, "a: b", "--- \"a: b\"\n"},
+		{line(), cfgSorted, math.Inf(-1), "--- -.inf\n"},
+		{line(), cfgSorted, []byte("hi"), "--- !!binary aGk=\n"},
+		{line(), cfgSorted, []int{}, "--- []\n"},
+		{line(), cfgSorted, map[string]int{}, "--- {}\n"},
+		{line(), cfgSorted, []int(nil), "--- null\n"},
+		{line(), cfgSorted, []any{1, "a", nil}, "---\n- 1\n- a\n- null\n"},
+		{line(), cfgSorted, [][]int{{1, 2}, {3}}, "---\n- - 1\n  - 2\n- - 3\n"},
+		{line(), cfgSorted, map[int][]string{2: {"b"}, 1: {"a"}},
+			"---\n\"1\":\n  - a\n\"2\":\n  - b\n"},
+		{line(), cfgSorted, stringer("x"), "--- stringer x\n"},
+		{line(), cfgNoMethods, stringer("x"), "--- x\n"},
+		{line(), cfgSorted, yamlNode{Name: "a", id: 1},
+			"---\nName: a\nItems: null\nUp: null\nid: 1\n"},
+		{line(), cfgNoUnexported, yamlNode{Name: "a", id: 1},
+			"---\nName: a\nItems: null\nUp: null\n"},
+		{line(), cfgSorted, tree,
+			"--- &id001\n" +
+				"Name: root\n" +
+				"Items:\n" +
+				"  - Name: a\n" +
+				"    Items: null\n" +
+				"    Up: *id001\n" +
+				"    id: 0\n" +
+				"  - Name: b\n" +
+				"    Items: null\n" +
+				"    Up: *id001\n" +
+				"    id: 0\n" +
+				"Up: null\n" +
+				"id: 0\n"},
+		{line(), cfgSorted, twice,
+			"---\n" +
+				"- &id001\n" +
+				"  Name: shared\n" +
+				"  Items: null\n" +
+				"  Up: null\n" +
+				"  id: 0\n" +
+				"- *id001\n"},
+		{line(), cfgIndent, []yamlNode{{Name: "a"}},
+			"---\n" +
+				"-   Name: a\n" +
+				"    Items: null\n" +
+				"    Up: null\n" +
+				"    id: 0\n"},
+		{line(), cfgMaxDepth, tree,
+			"--- &id001\n" +
+				"Name: root\n" +
+				"Items: \"<max depth reached>\"\n" +
+				"Up: null\n" +
+				"id: 0\n"},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for _, test := range tests {
+		s := test.cfg.Syaml(test.in)
+		if s != test.want {
+			t.Errorf("testcase on line %s:\n got: %s\nwant: %s", test.line, s, test.want)
+		}
+	}
+
+	if s := spew.Syaml(1); s != "--- 1\n" {
+		t.Errorf("spew.Syaml:\n got: %s", s)
+	}
+}